@@ -8,7 +8,10 @@ import (
 	"time"
 
 	connect "github.com/bufbuild/connect-go"
+	grpchealth "github.com/bufbuild/connect-grpchealth-go"
 	"github.com/bufbuild/protovalidate-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1/eventsv1connect"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1/idmv1connect"
 	"github.com/tierklinik-dobersberg/apis/pkg/auth"
@@ -20,12 +23,24 @@ import (
 	"github.com/tierklinik-dobersberg/apis/pkg/log"
 	"github.com/tierklinik-dobersberg/apis/pkg/server"
 	"github.com/tierklinik-dobersberg/apis/pkg/validator"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/bundle"
 	"github.com/tierklinik-dobersberg/events-service/internal/broker"
 	"github.com/tierklinik-dobersberg/events-service/internal/codec"
 	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/deadletter"
+	"github.com/tierklinik-dobersberg/events-service/internal/health"
+	"github.com/tierklinik-dobersberg/events-service/internal/history"
+	"github.com/tierklinik-dobersberg/events-service/internal/httpauth"
+	"github.com/tierklinik-dobersberg/events-service/internal/leader"
+	"github.com/tierklinik-dobersberg/events-service/internal/ratelimit"
 	"github.com/tierklinik-dobersberg/events-service/internal/service"
+	"github.com/tierklinik-dobersberg/events-service/internal/sse"
+	"github.com/tierklinik-dobersberg/events-service/internal/storage"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
+	"github.com/tierklinik-dobersberg/events-service/internal/webhook"
+	"github.com/tierklinik-dobersberg/events-service/internal/ws"
 	"github.com/tierklinik-dobersberg/pbtype-server/pkg/resolver"
 	"google.golang.org/protobuf/reflect/protoregistry"
 
@@ -38,6 +53,7 @@ import (
 	_ "github.com/tierklinik-dobersberg/events-service/internal/automation/modules/fetch"
 	_ "github.com/tierklinik-dobersberg/events-service/internal/automation/modules/fs"
 	_ "github.com/tierklinik-dobersberg/events-service/internal/automation/modules/path"
+	_ "github.com/tierklinik-dobersberg/events-service/internal/automation/modules/storage"
 	_ "github.com/tierklinik-dobersberg/events-service/internal/automation/modules/template"
 	_ "github.com/tierklinik-dobersberg/events-service/internal/automation/modules/timeutil"
 )
@@ -54,6 +70,26 @@ func main() {
 		os.Exit(-1)
 	}
 
+	// setup trace export
+	if cfg.TracingEndpoint != "" {
+		shutdown, err := tracing.Setup(ctx, cfg.TracingEndpoint, cfg.TracingSampleRatio)
+		if err != nil {
+			slog.Error("failed to setup tracing", "error", err)
+			os.Exit(-1)
+		}
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := shutdown(ctx); err != nil {
+				slog.Error("failed to flush pending spans", "error", err)
+			}
+		}()
+
+		slog.Info("exporting traces", "endpoint", cfg.TracingEndpoint, "sampleRatio", cfg.TracingSampleRatio)
+	}
+
 	protoValidator, err := protovalidate.New()
 	if err != nil {
 		slog.Error("failed to prepare protovalidate", slog.Any("error", err.Error()))
@@ -66,29 +102,38 @@ func main() {
 		validator.NewInterceptor(protoValidator),
 	)
 
+	// authenticator is used for plain HTTP endpoints like SSE and WebSocket
+	// as well as connect streams, which are not handled by the auth
+	// interceptor. It stays nil if no IDM is configured.
+	var authenticator *httpauth.Authenticator
+
 	if cfg.IdmURL != "" {
 		roleClient := idmv1connect.NewRoleServiceClient(http.DefaultClient, cfg.IdmURL)
 
+		extractor := func(ctx context.Context, req connect.AnyRequest) (auth.RemoteUser, error) {
+			serverKey, _ := ctx.Value(serverContextKey).(string)
+
+			if serverKey == "admin" {
+				return auth.RemoteUser{
+					ID:          "service-account",
+					DisplayName: req.Peer().Addr,
+					RoleIDs:     []string{"idm_superuser"}, // FIXME(ppacher): use a dedicated manager role for this
+					Admin:       true,
+				}, nil
+			}
+
+			return auth.RemoteHeaderExtractor(ctx, req)
+		}
+
 		authInterceptor := auth.NewAuthAnnotationInterceptor(
 			protoregistry.GlobalFiles,
 			auth.NewIDMRoleResolver(roleClient),
-			func(ctx context.Context, req connect.AnyRequest) (auth.RemoteUser, error) {
-				serverKey, _ := ctx.Value(serverContextKey).(string)
-
-				if serverKey == "admin" {
-					return auth.RemoteUser{
-						ID:          "service-account",
-						DisplayName: req.Peer().Addr,
-						RoleIDs:     []string{"idm_superuser"}, // FIXME(ppacher): use a dedicated manager role for this
-						Admin:       true,
-					}, nil
-				}
-
-				return auth.RemoteHeaderExtractor(ctx, req)
-			},
+			extractor,
 		)
 
 		interceptors = connect.WithOptions(interceptors, connect.WithInterceptors(authInterceptor))
+		// SSE and WebSocket subscriptions are checked like the Subscribe RPC
+		authenticator = httpauth.New(eventsv1connect.EventServiceSubscribeProcedure, authInterceptor)
 	}
 
 	corsConfig := cors.Config{
@@ -96,13 +141,61 @@ func main() {
 		AllowCredentials: true,
 	}
 
-	b, err := broker.NewMQTTBroker(ctx, cfg.MqttURL)
+	// If we got a type-server URL we use a custom codec for marshaling
+	var typeResolver codec.Resolver
+	if cfg.TypeServerURL != "" {
+		slog.Info("using type-server", "url", cfg.TypeServerURL)
+		typeResolver = resolver.Wrap(cfg.TypeServerURL, protoregistry.GlobalFiles, protoregistry.GlobalTypes)
+		interceptors = connect.WithOptions(interceptors, connect.WithCodec(codec.NewCodec(typeResolver)))
+	}
+
+	var brokerOptions []broker.Option
+
+	if len(cfg.RetainedKeys) > 0 {
+		brokerOptions = append(brokerOptions, broker.WithRetainedKeys(cfg.RetainedKeys, typeResolver))
+	}
+
+	// setup the publish spool
+	if cfg.SpoolPath != "" {
+		spool, err := broker.OpenSpool(cfg.SpoolPath, cfg.SpoolMaxEvents)
+		if err != nil {
+			slog.Error("failed to open publish spool", "error", err)
+			os.Exit(-1)
+		}
+		defer spool.Close()
+
+		prometheus.MustRegister(
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: "events_service",
+				Name:      "spool_depth",
+				Help:      "Number of events waiting in the publish spool.",
+			}, func() float64 {
+				return float64(spool.Len())
+			}),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace: "events_service",
+				Name:      "spool_dropped_total",
+				Help:      "Number of events rejected because the publish spool was full.",
+			}, func() float64 {
+				return float64(spool.Dropped())
+			}),
+		)
+
+		brokerOptions = append(brokerOptions, broker.WithSpool(spool))
+
+		slog.Info("publish spool enabled", "path", cfg.SpoolPath, "depth", spool.Len())
+	}
+
+	b, err := broker.NewMQTTBroker(ctx, cfg.MqttURL, brokerOptions...)
 	if err != nil {
 		slog.Error("failed to connect to MQTT broker", slog.Any("error", err.Error()))
 		os.Exit(-1)
 	}
 
-	svc, err := service.NewEventsService(b)
+	limiter := ratelimit.New(cfg.RateLimits)
+	limiter.Start(ctx)
+
+	svc, err := service.NewEventsService(b, limiter, authenticator)
 	if err != nil {
 		slog.Error("failed to create EventsService", slog.Any("error", err.Error()))
 		os.Exit(-1)
@@ -110,16 +203,110 @@ func main() {
 
 	serveMux := http.NewServeMux()
 
-	// If we got a type-server URL we use a custom codec for marshaling
+	// health and readiness checks
+	checker := health.New(eventsv1connect.EventServiceName)
+	checker.Add("mqtt", health.Connected("mqtt", b.Connected))
+
 	if cfg.TypeServerURL != "" {
-		slog.Info("using type-server", "url", cfg.TypeServerURL)
-		resolver := resolver.Wrap(cfg.TypeServerURL, protoregistry.GlobalFiles, protoregistry.GlobalTypes)
-		interceptors = connect.WithOptions(interceptors, connect.WithCodec(codec.NewCodec(resolver)))
+		checker.Add("type-server", health.Reachable(http.DefaultClient, cfg.TypeServerURL))
 	}
 
+	serveMux.Handle("/healthz", checker.LivenessHandler())
+	serveMux.Handle("/readyz", checker.ReadinessHandler())
+	serveMux.Handle(grpchealth.NewHandler(checker))
+
 	path, handler := eventsv1connect.NewEventServiceHandler(svc, interceptors)
 	serveMux.Handle(path, handler)
 
+	// setup the event history
+	var (
+		historyService *service.HistoryService
+		historyBroker  *broker.Broker
+	)
+
+	if cfg.HistoryPath != "" {
+		store, err := history.Open(cfg.HistoryPath, typeResolver)
+		if err != nil {
+			slog.Error("failed to open event history", "error", err)
+			os.Exit(-1)
+		}
+		defer store.Close()
+
+		// the history uses a dedicated MQTT connection for the wildcard subscription
+		// so other subscribers never receive duplicate messages for overlapping
+		// subscriptions. The retained keys are required to de-duplicate the
+		// retained state of each entity.
+		historyOptions := []broker.Option{broker.WithName("history")}
+		if len(cfg.RetainedKeys) > 0 {
+			historyOptions = append(historyOptions, broker.WithRetainedKeys(cfg.RetainedKeys, typeResolver))
+		}
+
+		historyBroker, err = broker.NewMQTTBroker(ctx, cfg.MqttURL, historyOptions...)
+		if err != nil {
+			slog.Error("failed to connect to MQTT broker", slog.Any("error", err.Error()))
+			os.Exit(-1)
+		}
+
+		go store.Run(ctx, historyBroker, cfg.HistoryRetention)
+
+		checker.Add("mqtt-history", health.Connected("mqtt-history", historyBroker.Connected))
+
+		historyService = service.NewHistoryService(store)
+	}
+
+	// retained event snapshots, listing all retained events requires the
+	// wildcard subscription of the history connection.
+	retained := service.NewRetainedService(b, historyBroker)
+
+	path, handler = eventsservicev1connect.NewRetainedServiceHandler(retained, interceptors)
+	serveMux.Handle(path, handler)
+
+	// Server-Sent Events for browsers that cannot use connect streams
+	serveMux.Handle("/events/sse", sse.NewHandler(ctx, b, typeResolver, authenticator, limiter))
+
+	// WebSocket gateway for clients that cannot use HTTP/2 streaming
+	serveMux.Handle("/events/ws", ws.NewHandler(b, typeResolver, cfg.AllowedOrigins, authenticator, limiter))
+
+	// the admin listener serves everything from serveMux and some additional,
+	// admin-only endpoints.
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/", serveMux)
+
+	path, handler = eventsservicev1connect.NewRetainedAdminServiceHandler(retained, interceptors)
+	adminMux.Handle(path, handler)
+
+	path, handler = eventsservicev1connect.NewIntrospectionServiceHandler(service.NewIntrospectionService(b), interceptors)
+	adminMux.Handle(path, handler)
+
+	// the event history is not protected by role annotations and thus only
+	// available on the admin listener.
+	if historyService != nil {
+		path, handler = eventsservicev1connect.NewEventHistoryServiceHandler(historyService, interceptors)
+		adminMux.Handle(path, handler)
+	}
+
+	// expose the current rate limit usage
+	adminMux.Handle("/ratelimits", limiter)
+
+	// expose prometheus metrics
+	adminMux.Handle("/metrics", promhttp.Handler())
+
+	// setup outgoing webhooks
+	if cfg.WebhookConfig != "" {
+		endpoints, err := webhook.LoadFile(cfg.WebhookConfig)
+		if err != nil {
+			slog.Error("failed to load webhook configuration", "error", err)
+			os.Exit(-1)
+		}
+
+		webhooks := webhook.NewManager(b, typeResolver, endpoints)
+		webhooks.Start(ctx)
+
+		adminMux.Handle("/webhooks", webhooks)
+
+		slog.Info("webhooks configured", "count", len(endpoints))
+	}
+
 	loggingHandler := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -151,36 +338,115 @@ func main() {
 		slog.Info("consul service catalog configured, registration and service discovery enabled")
 	}
 
+	// setup leader election for automation schedules
+	var elector leader.Elector = leader.Always{}
+	if cfg.ConsulAddress != "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			slog.Error("failed to get hostname", "error", err)
+			os.Exit(-1)
+		}
+
+		consulElector, err := leader.NewConsul(cfg.ConsulAddress, cfg.LeaderElectionKey, hostname)
+		if err != nil {
+			slog.Error("failed to create leader elector", "error", err)
+			os.Exit(-1)
+		}
+
+		go consulElector.Run(ctx)
+
+		elector = consulElector
+	} else {
+		slog.Warn("no consul instance configured, automation schedules will run on every replica")
+	}
+
 	options := []automation.EngineOption{
 		automation.WithDiscoverer(catalog),
+		automation.WithLeaderElector(elector),
 	}
 
-	// setup automation framework
-	if cfg.ScriptPath != "" {
-		slog.Info("searching for automation bundles", "path", cfg.ScriptPath)
+	// bundles manages the automation bundles and is set below if
+	// AUTOMATION_PATH is configured.
+	var bundles *bundle.Manager
 
-		bundles, err := bundle.Discover(cfg.ScriptPath)
+	// setup the dead-letter queue for failed automation handlers
+	if cfg.DeadLetterPath != "" {
+		deadLetters, err := deadletter.Open(cfg.DeadLetterPath)
 		if err != nil {
-			slog.Error("failed to discover automation bundles", "error", err)
-		} else {
-			if len(bundles) == 0 {
-				slog.Info("no automation bundles discovered")
-			} else {
-				slog.Info("automation bundles discovered successfully", "count", len(bundles))
-			}
+			slog.Error("failed to open dead-letter queue", "error", err)
+			os.Exit(-1)
+		}
+		defer deadLetters.Close()
 
-			for _, bundle := range bundles {
-				if err := bundle.Prepare(*cfg, b, options...); err != nil {
-					slog.Error("failed to prepare bundle", "name", bundle.Path, "error", err)
-					continue
-				}
+		options = append(options, automation.WithDeadLetterQueue(deadLetters))
 
-				slog.Info("successfully prepare automation bundle", "name", bundle.Path)
+		path, handler := eventsservicev1connect.NewDeadLetterServiceHandler(service.NewDeadLetterService(deadLetters, func(name string) *automation.Engine {
+			if bundles == nil {
+				return nil
 			}
+
+			return bundles.Engine(name)
+		}), interceptors)
+		adminMux.Handle(path, handler)
+	}
+
+	// setup the key/value storage for automation bundles
+	if cfg.StoragePath != "" {
+		kv, err := storage.Open(cfg.StoragePath)
+		if err != nil {
+			slog.Error("failed to open storage", "error", err)
+			os.Exit(-1)
 		}
+		defer kv.Close()
+
+		go kv.Run(ctx)
+
+		options = append(options, automation.WithStorage(kv))
+
+		path, handler := eventsservicev1connect.NewStorageServiceHandler(service.NewStorageService(kv), interceptors)
+		adminMux.Handle(path, handler)
+	}
+
+	// setup automation framework
+	if cfg.ScriptPath != "" {
+		slog.Info("loading automation bundles", "path", cfg.ScriptPath)
+
+		bundles = bundle.NewManager(cfg.ScriptPath, *cfg, b, options...)
+		bundles.Sync()
+
+		slog.Info("automation bundles loaded", "count", len(bundles.Bundles()))
+
+		// broken bundles are reported but must not take the instance out of
+		// rotation.
+		checker.AddInformational("automation", bundles.Check)
+
+		path, handler := eventsservicev1connect.NewAutomationServiceHandler(
+			service.NewAutomationService(bundles),
+			interceptors,
+			// leave room for the base64 encoding of the JSON codec
+			connect.WithReadMaxBytes(2*bundle.MaxUploadSize),
+		)
+		adminMux.Handle(path, handler)
+
+		// the manager always runs so all engines are stopped on shutdown,
+		// the reload interval only controls whether AUTOMATION_PATH is
+		// polled for changes.
+		managerCtx, stopManager := context.WithCancel(ctx)
+		managerDone := make(chan struct{})
+
+		go func() {
+			defer close(managerDone)
+			bundles.Run(managerCtx, cfg.AutomationReloadInterval)
+		}()
+
+		defer func() {
+			stopManager()
+			<-managerDone
+		}()
 	}
 
-	if err := discovery.Register(ctx, catalog, &discovery.ServiceInstance{
+	// the service is only registered at the catalog while it's ready
+	if err := checker.Register(ctx, catalog, discovery.ServiceInstance{
 		Name:    wellknown.EventV1ServiceScropt,
 		Address: cfg.AdminListenAddress,
 	}); err != nil {
@@ -194,7 +460,7 @@ func main() {
 		os.Exit(-1)
 	}
 
-	adminServer, err := server.CreateWithOptions(cfg.AdminListenAddress, wrapWithKey("admin", loggingHandler(serveMux)), server.WithCORS(corsConfig))
+	adminServer, err := server.CreateWithOptions(cfg.AdminListenAddress, wrapWithKey("admin", loggingHandler(adminMux)), server.WithCORS(corsConfig))
 	if err != nil {
 		slog.Error("failed to setup admin-server", slog.Any("error", err.Error()))
 		os.Exit(-1)
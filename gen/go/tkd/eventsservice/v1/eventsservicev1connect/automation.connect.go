@@ -0,0 +1,319 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/eventsservice/v1/automation.proto
+
+package eventsservicev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// AutomationServiceName is the fully-qualified name of the AutomationService service.
+	AutomationServiceName = "tkd.eventsservice.v1.AutomationService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AutomationServiceListBundlesProcedure is the fully-qualified name of the AutomationService's
+	// ListBundles RPC.
+	AutomationServiceListBundlesProcedure = "/tkd.eventsservice.v1.AutomationService/ListBundles"
+	// AutomationServiceUploadBundleProcedure is the fully-qualified name of the AutomationService's
+	// UploadBundle RPC.
+	AutomationServiceUploadBundleProcedure = "/tkd.eventsservice.v1.AutomationService/UploadBundle"
+	// AutomationServiceEnableBundleProcedure is the fully-qualified name of the AutomationService's
+	// EnableBundle RPC.
+	AutomationServiceEnableBundleProcedure = "/tkd.eventsservice.v1.AutomationService/EnableBundle"
+	// AutomationServiceDisableBundleProcedure is the fully-qualified name of the AutomationService's
+	// DisableBundle RPC.
+	AutomationServiceDisableBundleProcedure = "/tkd.eventsservice.v1.AutomationService/DisableBundle"
+	// AutomationServiceDeleteBundleProcedure is the fully-qualified name of the AutomationService's
+	// DeleteBundle RPC.
+	AutomationServiceDeleteBundleProcedure = "/tkd.eventsservice.v1.AutomationService/DeleteBundle"
+	// AutomationServiceUpdateBundleParametersProcedure is the fully-qualified name of the
+	// AutomationService's UpdateBundleParameters RPC.
+	AutomationServiceUpdateBundleParametersProcedure = "/tkd.eventsservice.v1.AutomationService/UpdateBundleParameters"
+	// AutomationServiceListBundleLogsProcedure is the fully-qualified name of the AutomationService's
+	// ListBundleLogs RPC.
+	AutomationServiceListBundleLogsProcedure = "/tkd.eventsservice.v1.AutomationService/ListBundleLogs"
+	// AutomationServiceTailBundleLogsProcedure is the fully-qualified name of the AutomationService's
+	// TailBundleLogs RPC.
+	AutomationServiceTailBundleLogsProcedure = "/tkd.eventsservice.v1.AutomationService/TailBundleLogs"
+)
+
+// AutomationServiceClient is a client for the tkd.eventsservice.v1.AutomationService service.
+type AutomationServiceClient interface {
+	// ListBundles returns all bundles in AUTOMATION_PATH.
+	ListBundles(context.Context, *connect_go.Request[v1.ListBundlesRequest]) (*connect_go.Response[v1.ListBundlesResponse], error)
+	// UploadBundle stores a bundle archive in AUTOMATION_PATH and loads it.
+	UploadBundle(context.Context, *connect_go.Request[v1.UploadBundleRequest]) (*connect_go.Response[v1.UploadBundleResponse], error)
+	// EnableBundle starts a disabled bundle.
+	EnableBundle(context.Context, *connect_go.Request[v1.EnableBundleRequest]) (*connect_go.Response[v1.EnableBundleResponse], error)
+	// DisableBundle stops a bundle until it is enabled again. The state is
+	// kept across restarts.
+	DisableBundle(context.Context, *connect_go.Request[v1.DisableBundleRequest]) (*connect_go.Response[v1.DisableBundleResponse], error)
+	// DeleteBundle stops a bundle and removes it from AUTOMATION_PATH.
+	DeleteBundle(context.Context, *connect_go.Request[v1.DeleteBundleRequest]) (*connect_go.Response[v1.DeleteBundleResponse], error)
+	// UpdateBundleParameters replaces the vars.json of a bundle and reloads
+	// it.
+	UpdateBundleParameters(context.Context, *connect_go.Request[v1.UpdateBundleParametersRequest]) (*connect_go.Response[v1.UpdateBundleParametersResponse], error)
+	// ListBundleLogs returns the buffered console messages of a bundle.
+	ListBundleLogs(context.Context, *connect_go.Request[v1.ListBundleLogsRequest]) (*connect_go.Response[v1.ListBundleLogsResponse], error)
+	// TailBundleLogs streams the console messages of a bundle as they are
+	// logged. The stream ends when the bundle is removed. Messages are
+	// dropped for clients that cannot keep up.
+	TailBundleLogs(context.Context, *connect_go.Request[v1.TailBundleLogsRequest]) (*connect_go.ServerStreamForClient[v1.TailBundleLogsResponse], error)
+}
+
+// NewAutomationServiceClient constructs a client for the tkd.eventsservice.v1.AutomationService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAutomationServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) AutomationServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &automationServiceClient{
+		listBundles: connect_go.NewClient[v1.ListBundlesRequest, v1.ListBundlesResponse](
+			httpClient,
+			baseURL+AutomationServiceListBundlesProcedure,
+			opts...,
+		),
+		uploadBundle: connect_go.NewClient[v1.UploadBundleRequest, v1.UploadBundleResponse](
+			httpClient,
+			baseURL+AutomationServiceUploadBundleProcedure,
+			opts...,
+		),
+		enableBundle: connect_go.NewClient[v1.EnableBundleRequest, v1.EnableBundleResponse](
+			httpClient,
+			baseURL+AutomationServiceEnableBundleProcedure,
+			opts...,
+		),
+		disableBundle: connect_go.NewClient[v1.DisableBundleRequest, v1.DisableBundleResponse](
+			httpClient,
+			baseURL+AutomationServiceDisableBundleProcedure,
+			opts...,
+		),
+		deleteBundle: connect_go.NewClient[v1.DeleteBundleRequest, v1.DeleteBundleResponse](
+			httpClient,
+			baseURL+AutomationServiceDeleteBundleProcedure,
+			opts...,
+		),
+		updateBundleParameters: connect_go.NewClient[v1.UpdateBundleParametersRequest, v1.UpdateBundleParametersResponse](
+			httpClient,
+			baseURL+AutomationServiceUpdateBundleParametersProcedure,
+			opts...,
+		),
+		listBundleLogs: connect_go.NewClient[v1.ListBundleLogsRequest, v1.ListBundleLogsResponse](
+			httpClient,
+			baseURL+AutomationServiceListBundleLogsProcedure,
+			opts...,
+		),
+		tailBundleLogs: connect_go.NewClient[v1.TailBundleLogsRequest, v1.TailBundleLogsResponse](
+			httpClient,
+			baseURL+AutomationServiceTailBundleLogsProcedure,
+			opts...,
+		),
+	}
+}
+
+// automationServiceClient implements AutomationServiceClient.
+type automationServiceClient struct {
+	listBundles            *connect_go.Client[v1.ListBundlesRequest, v1.ListBundlesResponse]
+	uploadBundle           *connect_go.Client[v1.UploadBundleRequest, v1.UploadBundleResponse]
+	enableBundle           *connect_go.Client[v1.EnableBundleRequest, v1.EnableBundleResponse]
+	disableBundle          *connect_go.Client[v1.DisableBundleRequest, v1.DisableBundleResponse]
+	deleteBundle           *connect_go.Client[v1.DeleteBundleRequest, v1.DeleteBundleResponse]
+	updateBundleParameters *connect_go.Client[v1.UpdateBundleParametersRequest, v1.UpdateBundleParametersResponse]
+	listBundleLogs         *connect_go.Client[v1.ListBundleLogsRequest, v1.ListBundleLogsResponse]
+	tailBundleLogs         *connect_go.Client[v1.TailBundleLogsRequest, v1.TailBundleLogsResponse]
+}
+
+// ListBundles calls tkd.eventsservice.v1.AutomationService.ListBundles.
+func (c *automationServiceClient) ListBundles(ctx context.Context, req *connect_go.Request[v1.ListBundlesRequest]) (*connect_go.Response[v1.ListBundlesResponse], error) {
+	return c.listBundles.CallUnary(ctx, req)
+}
+
+// UploadBundle calls tkd.eventsservice.v1.AutomationService.UploadBundle.
+func (c *automationServiceClient) UploadBundle(ctx context.Context, req *connect_go.Request[v1.UploadBundleRequest]) (*connect_go.Response[v1.UploadBundleResponse], error) {
+	return c.uploadBundle.CallUnary(ctx, req)
+}
+
+// EnableBundle calls tkd.eventsservice.v1.AutomationService.EnableBundle.
+func (c *automationServiceClient) EnableBundle(ctx context.Context, req *connect_go.Request[v1.EnableBundleRequest]) (*connect_go.Response[v1.EnableBundleResponse], error) {
+	return c.enableBundle.CallUnary(ctx, req)
+}
+
+// DisableBundle calls tkd.eventsservice.v1.AutomationService.DisableBundle.
+func (c *automationServiceClient) DisableBundle(ctx context.Context, req *connect_go.Request[v1.DisableBundleRequest]) (*connect_go.Response[v1.DisableBundleResponse], error) {
+	return c.disableBundle.CallUnary(ctx, req)
+}
+
+// DeleteBundle calls tkd.eventsservice.v1.AutomationService.DeleteBundle.
+func (c *automationServiceClient) DeleteBundle(ctx context.Context, req *connect_go.Request[v1.DeleteBundleRequest]) (*connect_go.Response[v1.DeleteBundleResponse], error) {
+	return c.deleteBundle.CallUnary(ctx, req)
+}
+
+// UpdateBundleParameters calls tkd.eventsservice.v1.AutomationService.UpdateBundleParameters.
+func (c *automationServiceClient) UpdateBundleParameters(ctx context.Context, req *connect_go.Request[v1.UpdateBundleParametersRequest]) (*connect_go.Response[v1.UpdateBundleParametersResponse], error) {
+	return c.updateBundleParameters.CallUnary(ctx, req)
+}
+
+// ListBundleLogs calls tkd.eventsservice.v1.AutomationService.ListBundleLogs.
+func (c *automationServiceClient) ListBundleLogs(ctx context.Context, req *connect_go.Request[v1.ListBundleLogsRequest]) (*connect_go.Response[v1.ListBundleLogsResponse], error) {
+	return c.listBundleLogs.CallUnary(ctx, req)
+}
+
+// TailBundleLogs calls tkd.eventsservice.v1.AutomationService.TailBundleLogs.
+func (c *automationServiceClient) TailBundleLogs(ctx context.Context, req *connect_go.Request[v1.TailBundleLogsRequest]) (*connect_go.ServerStreamForClient[v1.TailBundleLogsResponse], error) {
+	return c.tailBundleLogs.CallServerStream(ctx, req)
+}
+
+// AutomationServiceHandler is an implementation of the tkd.eventsservice.v1.AutomationService
+// service.
+type AutomationServiceHandler interface {
+	// ListBundles returns all bundles in AUTOMATION_PATH.
+	ListBundles(context.Context, *connect_go.Request[v1.ListBundlesRequest]) (*connect_go.Response[v1.ListBundlesResponse], error)
+	// UploadBundle stores a bundle archive in AUTOMATION_PATH and loads it.
+	UploadBundle(context.Context, *connect_go.Request[v1.UploadBundleRequest]) (*connect_go.Response[v1.UploadBundleResponse], error)
+	// EnableBundle starts a disabled bundle.
+	EnableBundle(context.Context, *connect_go.Request[v1.EnableBundleRequest]) (*connect_go.Response[v1.EnableBundleResponse], error)
+	// DisableBundle stops a bundle until it is enabled again. The state is
+	// kept across restarts.
+	DisableBundle(context.Context, *connect_go.Request[v1.DisableBundleRequest]) (*connect_go.Response[v1.DisableBundleResponse], error)
+	// DeleteBundle stops a bundle and removes it from AUTOMATION_PATH.
+	DeleteBundle(context.Context, *connect_go.Request[v1.DeleteBundleRequest]) (*connect_go.Response[v1.DeleteBundleResponse], error)
+	// UpdateBundleParameters replaces the vars.json of a bundle and reloads
+	// it.
+	UpdateBundleParameters(context.Context, *connect_go.Request[v1.UpdateBundleParametersRequest]) (*connect_go.Response[v1.UpdateBundleParametersResponse], error)
+	// ListBundleLogs returns the buffered console messages of a bundle.
+	ListBundleLogs(context.Context, *connect_go.Request[v1.ListBundleLogsRequest]) (*connect_go.Response[v1.ListBundleLogsResponse], error)
+	// TailBundleLogs streams the console messages of a bundle as they are
+	// logged. The stream ends when the bundle is removed. Messages are
+	// dropped for clients that cannot keep up.
+	TailBundleLogs(context.Context, *connect_go.Request[v1.TailBundleLogsRequest], *connect_go.ServerStream[v1.TailBundleLogsResponse]) error
+}
+
+// NewAutomationServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAutomationServiceHandler(svc AutomationServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	automationServiceListBundlesHandler := connect_go.NewUnaryHandler(
+		AutomationServiceListBundlesProcedure,
+		svc.ListBundles,
+		opts...,
+	)
+	automationServiceUploadBundleHandler := connect_go.NewUnaryHandler(
+		AutomationServiceUploadBundleProcedure,
+		svc.UploadBundle,
+		opts...,
+	)
+	automationServiceEnableBundleHandler := connect_go.NewUnaryHandler(
+		AutomationServiceEnableBundleProcedure,
+		svc.EnableBundle,
+		opts...,
+	)
+	automationServiceDisableBundleHandler := connect_go.NewUnaryHandler(
+		AutomationServiceDisableBundleProcedure,
+		svc.DisableBundle,
+		opts...,
+	)
+	automationServiceDeleteBundleHandler := connect_go.NewUnaryHandler(
+		AutomationServiceDeleteBundleProcedure,
+		svc.DeleteBundle,
+		opts...,
+	)
+	automationServiceUpdateBundleParametersHandler := connect_go.NewUnaryHandler(
+		AutomationServiceUpdateBundleParametersProcedure,
+		svc.UpdateBundleParameters,
+		opts...,
+	)
+	automationServiceListBundleLogsHandler := connect_go.NewUnaryHandler(
+		AutomationServiceListBundleLogsProcedure,
+		svc.ListBundleLogs,
+		opts...,
+	)
+	automationServiceTailBundleLogsHandler := connect_go.NewServerStreamHandler(
+		AutomationServiceTailBundleLogsProcedure,
+		svc.TailBundleLogs,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.AutomationService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AutomationServiceListBundlesProcedure:
+			automationServiceListBundlesHandler.ServeHTTP(w, r)
+		case AutomationServiceUploadBundleProcedure:
+			automationServiceUploadBundleHandler.ServeHTTP(w, r)
+		case AutomationServiceEnableBundleProcedure:
+			automationServiceEnableBundleHandler.ServeHTTP(w, r)
+		case AutomationServiceDisableBundleProcedure:
+			automationServiceDisableBundleHandler.ServeHTTP(w, r)
+		case AutomationServiceDeleteBundleProcedure:
+			automationServiceDeleteBundleHandler.ServeHTTP(w, r)
+		case AutomationServiceUpdateBundleParametersProcedure:
+			automationServiceUpdateBundleParametersHandler.ServeHTTP(w, r)
+		case AutomationServiceListBundleLogsProcedure:
+			automationServiceListBundleLogsHandler.ServeHTTP(w, r)
+		case AutomationServiceTailBundleLogsProcedure:
+			automationServiceTailBundleLogsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAutomationServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedAutomationServiceHandler struct{}
+
+func (UnimplementedAutomationServiceHandler) ListBundles(context.Context, *connect_go.Request[v1.ListBundlesRequest]) (*connect_go.Response[v1.ListBundlesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.ListBundles is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) UploadBundle(context.Context, *connect_go.Request[v1.UploadBundleRequest]) (*connect_go.Response[v1.UploadBundleResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.UploadBundle is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) EnableBundle(context.Context, *connect_go.Request[v1.EnableBundleRequest]) (*connect_go.Response[v1.EnableBundleResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.EnableBundle is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) DisableBundle(context.Context, *connect_go.Request[v1.DisableBundleRequest]) (*connect_go.Response[v1.DisableBundleResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.DisableBundle is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) DeleteBundle(context.Context, *connect_go.Request[v1.DeleteBundleRequest]) (*connect_go.Response[v1.DeleteBundleResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.DeleteBundle is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) UpdateBundleParameters(context.Context, *connect_go.Request[v1.UpdateBundleParametersRequest]) (*connect_go.Response[v1.UpdateBundleParametersResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.UpdateBundleParameters is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) ListBundleLogs(context.Context, *connect_go.Request[v1.ListBundleLogsRequest]) (*connect_go.Response[v1.ListBundleLogsResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.ListBundleLogs is not implemented"))
+}
+
+func (UnimplementedAutomationServiceHandler) TailBundleLogs(context.Context, *connect_go.Request[v1.TailBundleLogsRequest], *connect_go.ServerStream[v1.TailBundleLogsResponse]) error {
+	return connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.AutomationService.TailBundleLogs is not implemented"))
+}
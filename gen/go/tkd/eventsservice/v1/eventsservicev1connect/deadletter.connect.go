@@ -0,0 +1,201 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/eventsservice/v1/deadletter.proto
+
+package eventsservicev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// DeadLetterServiceName is the fully-qualified name of the DeadLetterService service.
+	DeadLetterServiceName = "tkd.eventsservice.v1.DeadLetterService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// DeadLetterServiceListDeadLettersProcedure is the fully-qualified name of the DeadLetterService's
+	// ListDeadLetters RPC.
+	DeadLetterServiceListDeadLettersProcedure = "/tkd.eventsservice.v1.DeadLetterService/ListDeadLetters"
+	// DeadLetterServiceRedriveDeadLetterProcedure is the fully-qualified name of the
+	// DeadLetterService's RedriveDeadLetter RPC.
+	DeadLetterServiceRedriveDeadLetterProcedure = "/tkd.eventsservice.v1.DeadLetterService/RedriveDeadLetter"
+	// DeadLetterServiceRedriveAllDeadLettersProcedure is the fully-qualified name of the
+	// DeadLetterService's RedriveAllDeadLetters RPC.
+	DeadLetterServiceRedriveAllDeadLettersProcedure = "/tkd.eventsservice.v1.DeadLetterService/RedriveAllDeadLetters"
+	// DeadLetterServiceDeleteDeadLetterProcedure is the fully-qualified name of the DeadLetterService's
+	// DeleteDeadLetter RPC.
+	DeadLetterServiceDeleteDeadLetterProcedure = "/tkd.eventsservice.v1.DeadLetterService/DeleteDeadLetter"
+)
+
+// DeadLetterServiceClient is a client for the tkd.eventsservice.v1.DeadLetterService service.
+type DeadLetterServiceClient interface {
+	// ListDeadLetters returns all dead letters.
+	ListDeadLetters(context.Context, *connect_go.Request[v1.ListDeadLettersRequest]) (*connect_go.Response[v1.ListDeadLettersResponse], error)
+	// RedriveDeadLetter delivers a dead letter to the failed event handler of
+	// the automation bundle again. The dead letter is removed if the event has been
+	// handled successfully.
+	RedriveDeadLetter(context.Context, *connect_go.Request[v1.RedriveDeadLetterRequest]) (*connect_go.Response[v1.RedriveDeadLetterResponse], error)
+	// RedriveAllDeadLetters re-drives all dead letters, optionally limited to a
+	// single bundle.
+	RedriveAllDeadLetters(context.Context, *connect_go.Request[v1.RedriveAllDeadLettersRequest]) (*connect_go.Response[v1.RedriveAllDeadLettersResponse], error)
+	// DeleteDeadLetter removes a dead letter without re-driving it.
+	DeleteDeadLetter(context.Context, *connect_go.Request[v1.DeleteDeadLetterRequest]) (*connect_go.Response[v1.DeleteDeadLetterResponse], error)
+}
+
+// NewDeadLetterServiceClient constructs a client for the tkd.eventsservice.v1.DeadLetterService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewDeadLetterServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) DeadLetterServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &deadLetterServiceClient{
+		listDeadLetters: connect_go.NewClient[v1.ListDeadLettersRequest, v1.ListDeadLettersResponse](
+			httpClient,
+			baseURL+DeadLetterServiceListDeadLettersProcedure,
+			opts...,
+		),
+		redriveDeadLetter: connect_go.NewClient[v1.RedriveDeadLetterRequest, v1.RedriveDeadLetterResponse](
+			httpClient,
+			baseURL+DeadLetterServiceRedriveDeadLetterProcedure,
+			opts...,
+		),
+		redriveAllDeadLetters: connect_go.NewClient[v1.RedriveAllDeadLettersRequest, v1.RedriveAllDeadLettersResponse](
+			httpClient,
+			baseURL+DeadLetterServiceRedriveAllDeadLettersProcedure,
+			opts...,
+		),
+		deleteDeadLetter: connect_go.NewClient[v1.DeleteDeadLetterRequest, v1.DeleteDeadLetterResponse](
+			httpClient,
+			baseURL+DeadLetterServiceDeleteDeadLetterProcedure,
+			opts...,
+		),
+	}
+}
+
+// deadLetterServiceClient implements DeadLetterServiceClient.
+type deadLetterServiceClient struct {
+	listDeadLetters       *connect_go.Client[v1.ListDeadLettersRequest, v1.ListDeadLettersResponse]
+	redriveDeadLetter     *connect_go.Client[v1.RedriveDeadLetterRequest, v1.RedriveDeadLetterResponse]
+	redriveAllDeadLetters *connect_go.Client[v1.RedriveAllDeadLettersRequest, v1.RedriveAllDeadLettersResponse]
+	deleteDeadLetter      *connect_go.Client[v1.DeleteDeadLetterRequest, v1.DeleteDeadLetterResponse]
+}
+
+// ListDeadLetters calls tkd.eventsservice.v1.DeadLetterService.ListDeadLetters.
+func (c *deadLetterServiceClient) ListDeadLetters(ctx context.Context, req *connect_go.Request[v1.ListDeadLettersRequest]) (*connect_go.Response[v1.ListDeadLettersResponse], error) {
+	return c.listDeadLetters.CallUnary(ctx, req)
+}
+
+// RedriveDeadLetter calls tkd.eventsservice.v1.DeadLetterService.RedriveDeadLetter.
+func (c *deadLetterServiceClient) RedriveDeadLetter(ctx context.Context, req *connect_go.Request[v1.RedriveDeadLetterRequest]) (*connect_go.Response[v1.RedriveDeadLetterResponse], error) {
+	return c.redriveDeadLetter.CallUnary(ctx, req)
+}
+
+// RedriveAllDeadLetters calls tkd.eventsservice.v1.DeadLetterService.RedriveAllDeadLetters.
+func (c *deadLetterServiceClient) RedriveAllDeadLetters(ctx context.Context, req *connect_go.Request[v1.RedriveAllDeadLettersRequest]) (*connect_go.Response[v1.RedriveAllDeadLettersResponse], error) {
+	return c.redriveAllDeadLetters.CallUnary(ctx, req)
+}
+
+// DeleteDeadLetter calls tkd.eventsservice.v1.DeadLetterService.DeleteDeadLetter.
+func (c *deadLetterServiceClient) DeleteDeadLetter(ctx context.Context, req *connect_go.Request[v1.DeleteDeadLetterRequest]) (*connect_go.Response[v1.DeleteDeadLetterResponse], error) {
+	return c.deleteDeadLetter.CallUnary(ctx, req)
+}
+
+// DeadLetterServiceHandler is an implementation of the tkd.eventsservice.v1.DeadLetterService
+// service.
+type DeadLetterServiceHandler interface {
+	// ListDeadLetters returns all dead letters.
+	ListDeadLetters(context.Context, *connect_go.Request[v1.ListDeadLettersRequest]) (*connect_go.Response[v1.ListDeadLettersResponse], error)
+	// RedriveDeadLetter delivers a dead letter to the failed event handler of
+	// the automation bundle again. The dead letter is removed if the event has been
+	// handled successfully.
+	RedriveDeadLetter(context.Context, *connect_go.Request[v1.RedriveDeadLetterRequest]) (*connect_go.Response[v1.RedriveDeadLetterResponse], error)
+	// RedriveAllDeadLetters re-drives all dead letters, optionally limited to a
+	// single bundle.
+	RedriveAllDeadLetters(context.Context, *connect_go.Request[v1.RedriveAllDeadLettersRequest]) (*connect_go.Response[v1.RedriveAllDeadLettersResponse], error)
+	// DeleteDeadLetter removes a dead letter without re-driving it.
+	DeleteDeadLetter(context.Context, *connect_go.Request[v1.DeleteDeadLetterRequest]) (*connect_go.Response[v1.DeleteDeadLetterResponse], error)
+}
+
+// NewDeadLetterServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewDeadLetterServiceHandler(svc DeadLetterServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	deadLetterServiceListDeadLettersHandler := connect_go.NewUnaryHandler(
+		DeadLetterServiceListDeadLettersProcedure,
+		svc.ListDeadLetters,
+		opts...,
+	)
+	deadLetterServiceRedriveDeadLetterHandler := connect_go.NewUnaryHandler(
+		DeadLetterServiceRedriveDeadLetterProcedure,
+		svc.RedriveDeadLetter,
+		opts...,
+	)
+	deadLetterServiceRedriveAllDeadLettersHandler := connect_go.NewUnaryHandler(
+		DeadLetterServiceRedriveAllDeadLettersProcedure,
+		svc.RedriveAllDeadLetters,
+		opts...,
+	)
+	deadLetterServiceDeleteDeadLetterHandler := connect_go.NewUnaryHandler(
+		DeadLetterServiceDeleteDeadLetterProcedure,
+		svc.DeleteDeadLetter,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.DeadLetterService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case DeadLetterServiceListDeadLettersProcedure:
+			deadLetterServiceListDeadLettersHandler.ServeHTTP(w, r)
+		case DeadLetterServiceRedriveDeadLetterProcedure:
+			deadLetterServiceRedriveDeadLetterHandler.ServeHTTP(w, r)
+		case DeadLetterServiceRedriveAllDeadLettersProcedure:
+			deadLetterServiceRedriveAllDeadLettersHandler.ServeHTTP(w, r)
+		case DeadLetterServiceDeleteDeadLetterProcedure:
+			deadLetterServiceDeleteDeadLetterHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedDeadLetterServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedDeadLetterServiceHandler struct{}
+
+func (UnimplementedDeadLetterServiceHandler) ListDeadLetters(context.Context, *connect_go.Request[v1.ListDeadLettersRequest]) (*connect_go.Response[v1.ListDeadLettersResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.DeadLetterService.ListDeadLetters is not implemented"))
+}
+
+func (UnimplementedDeadLetterServiceHandler) RedriveDeadLetter(context.Context, *connect_go.Request[v1.RedriveDeadLetterRequest]) (*connect_go.Response[v1.RedriveDeadLetterResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.DeadLetterService.RedriveDeadLetter is not implemented"))
+}
+
+func (UnimplementedDeadLetterServiceHandler) RedriveAllDeadLetters(context.Context, *connect_go.Request[v1.RedriveAllDeadLettersRequest]) (*connect_go.Response[v1.RedriveAllDeadLettersResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.DeadLetterService.RedriveAllDeadLetters is not implemented"))
+}
+
+func (UnimplementedDeadLetterServiceHandler) DeleteDeadLetter(context.Context, *connect_go.Request[v1.DeleteDeadLetterRequest]) (*connect_go.Response[v1.DeleteDeadLetterResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.DeadLetterService.DeleteDeadLetter is not implemented"))
+}
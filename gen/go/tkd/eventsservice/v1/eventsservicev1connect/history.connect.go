@@ -0,0 +1,139 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/eventsservice/v1/history.proto
+
+package eventsservicev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// EventHistoryServiceName is the fully-qualified name of the EventHistoryService service.
+	EventHistoryServiceName = "tkd.eventsservice.v1.EventHistoryService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// EventHistoryServiceListEventsProcedure is the fully-qualified name of the EventHistoryService's
+	// ListEvents RPC.
+	EventHistoryServiceListEventsProcedure = "/tkd.eventsservice.v1.EventHistoryService/ListEvents"
+	// EventHistoryServiceGetLatestEventsProcedure is the fully-qualified name of the
+	// EventHistoryService's GetLatestEvents RPC.
+	EventHistoryServiceGetLatestEventsProcedure = "/tkd.eventsservice.v1.EventHistoryService/GetLatestEvents"
+)
+
+// EventHistoryServiceClient is a client for the tkd.eventsservice.v1.EventHistoryService service.
+type EventHistoryServiceClient interface {
+	// ListEvents returns all stored events of a given type that match the
+	// request.
+	ListEvents(context.Context, *connect_go.Request[v1.ListEventsRequest]) (*connect_go.Response[v1.ListEventsResponse], error)
+	// GetLatestEvents returns the last N events of a given type.
+	GetLatestEvents(context.Context, *connect_go.Request[v1.GetLatestEventsRequest]) (*connect_go.Response[v1.GetLatestEventsResponse], error)
+}
+
+// NewEventHistoryServiceClient constructs a client for the tkd.eventsservice.v1.EventHistoryService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewEventHistoryServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) EventHistoryServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &eventHistoryServiceClient{
+		listEvents: connect_go.NewClient[v1.ListEventsRequest, v1.ListEventsResponse](
+			httpClient,
+			baseURL+EventHistoryServiceListEventsProcedure,
+			opts...,
+		),
+		getLatestEvents: connect_go.NewClient[v1.GetLatestEventsRequest, v1.GetLatestEventsResponse](
+			httpClient,
+			baseURL+EventHistoryServiceGetLatestEventsProcedure,
+			opts...,
+		),
+	}
+}
+
+// eventHistoryServiceClient implements EventHistoryServiceClient.
+type eventHistoryServiceClient struct {
+	listEvents      *connect_go.Client[v1.ListEventsRequest, v1.ListEventsResponse]
+	getLatestEvents *connect_go.Client[v1.GetLatestEventsRequest, v1.GetLatestEventsResponse]
+}
+
+// ListEvents calls tkd.eventsservice.v1.EventHistoryService.ListEvents.
+func (c *eventHistoryServiceClient) ListEvents(ctx context.Context, req *connect_go.Request[v1.ListEventsRequest]) (*connect_go.Response[v1.ListEventsResponse], error) {
+	return c.listEvents.CallUnary(ctx, req)
+}
+
+// GetLatestEvents calls tkd.eventsservice.v1.EventHistoryService.GetLatestEvents.
+func (c *eventHistoryServiceClient) GetLatestEvents(ctx context.Context, req *connect_go.Request[v1.GetLatestEventsRequest]) (*connect_go.Response[v1.GetLatestEventsResponse], error) {
+	return c.getLatestEvents.CallUnary(ctx, req)
+}
+
+// EventHistoryServiceHandler is an implementation of the tkd.eventsservice.v1.EventHistoryService
+// service.
+type EventHistoryServiceHandler interface {
+	// ListEvents returns all stored events of a given type that match the
+	// request.
+	ListEvents(context.Context, *connect_go.Request[v1.ListEventsRequest]) (*connect_go.Response[v1.ListEventsResponse], error)
+	// GetLatestEvents returns the last N events of a given type.
+	GetLatestEvents(context.Context, *connect_go.Request[v1.GetLatestEventsRequest]) (*connect_go.Response[v1.GetLatestEventsResponse], error)
+}
+
+// NewEventHistoryServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewEventHistoryServiceHandler(svc EventHistoryServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	eventHistoryServiceListEventsHandler := connect_go.NewUnaryHandler(
+		EventHistoryServiceListEventsProcedure,
+		svc.ListEvents,
+		opts...,
+	)
+	eventHistoryServiceGetLatestEventsHandler := connect_go.NewUnaryHandler(
+		EventHistoryServiceGetLatestEventsProcedure,
+		svc.GetLatestEvents,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.EventHistoryService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EventHistoryServiceListEventsProcedure:
+			eventHistoryServiceListEventsHandler.ServeHTTP(w, r)
+		case EventHistoryServiceGetLatestEventsProcedure:
+			eventHistoryServiceGetLatestEventsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedEventHistoryServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedEventHistoryServiceHandler struct{}
+
+func (UnimplementedEventHistoryServiceHandler) ListEvents(context.Context, *connect_go.Request[v1.ListEventsRequest]) (*connect_go.Response[v1.ListEventsResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.EventHistoryService.ListEvents is not implemented"))
+}
+
+func (UnimplementedEventHistoryServiceHandler) GetLatestEvents(context.Context, *connect_go.Request[v1.GetLatestEventsRequest]) (*connect_go.Response[v1.GetLatestEventsResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.EventHistoryService.GetLatestEvents is not implemented"))
+}
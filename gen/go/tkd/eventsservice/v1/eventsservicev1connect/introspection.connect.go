@@ -0,0 +1,166 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/eventsservice/v1/introspection.proto
+
+package eventsservicev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// IntrospectionServiceName is the fully-qualified name of the IntrospectionService service.
+	IntrospectionServiceName = "tkd.eventsservice.v1.IntrospectionService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// IntrospectionServiceListTopicsProcedure is the fully-qualified name of the IntrospectionService's
+	// ListTopics RPC.
+	IntrospectionServiceListTopicsProcedure = "/tkd.eventsservice.v1.IntrospectionService/ListTopics"
+	// IntrospectionServiceListSubscribersProcedure is the fully-qualified name of the
+	// IntrospectionService's ListSubscribers RPC.
+	IntrospectionServiceListSubscribersProcedure = "/tkd.eventsservice.v1.IntrospectionService/ListSubscribers"
+	// IntrospectionServiceDisconnectSubscriberProcedure is the fully-qualified name of the
+	// IntrospectionService's DisconnectSubscriber RPC.
+	IntrospectionServiceDisconnectSubscriberProcedure = "/tkd.eventsservice.v1.IntrospectionService/DisconnectSubscriber"
+)
+
+// IntrospectionServiceClient is a client for the tkd.eventsservice.v1.IntrospectionService service.
+type IntrospectionServiceClient interface {
+	// ListTopics returns all MQTT topics the events-service is subscribed to.
+	ListTopics(context.Context, *connect_go.Request[v1.ListTopicsRequest]) (*connect_go.Response[v1.ListTopicsResponse], error)
+	// ListSubscribers returns all clients that are currently subscribed.
+	ListSubscribers(context.Context, *connect_go.Request[v1.ListSubscribersRequest]) (*connect_go.Response[v1.ListSubscribersResponse], error)
+	// DisconnectSubscriber forcibly ends the subscription of a client.
+	DisconnectSubscriber(context.Context, *connect_go.Request[v1.DisconnectSubscriberRequest]) (*connect_go.Response[v1.DisconnectSubscriberResponse], error)
+}
+
+// NewIntrospectionServiceClient constructs a client for the
+// tkd.eventsservice.v1.IntrospectionService service. By default, it uses the Connect protocol with
+// the binary Protobuf Codec, asks for gzipped responses, and sends uncompressed requests. To use
+// the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewIntrospectionServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) IntrospectionServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &introspectionServiceClient{
+		listTopics: connect_go.NewClient[v1.ListTopicsRequest, v1.ListTopicsResponse](
+			httpClient,
+			baseURL+IntrospectionServiceListTopicsProcedure,
+			opts...,
+		),
+		listSubscribers: connect_go.NewClient[v1.ListSubscribersRequest, v1.ListSubscribersResponse](
+			httpClient,
+			baseURL+IntrospectionServiceListSubscribersProcedure,
+			opts...,
+		),
+		disconnectSubscriber: connect_go.NewClient[v1.DisconnectSubscriberRequest, v1.DisconnectSubscriberResponse](
+			httpClient,
+			baseURL+IntrospectionServiceDisconnectSubscriberProcedure,
+			opts...,
+		),
+	}
+}
+
+// introspectionServiceClient implements IntrospectionServiceClient.
+type introspectionServiceClient struct {
+	listTopics           *connect_go.Client[v1.ListTopicsRequest, v1.ListTopicsResponse]
+	listSubscribers      *connect_go.Client[v1.ListSubscribersRequest, v1.ListSubscribersResponse]
+	disconnectSubscriber *connect_go.Client[v1.DisconnectSubscriberRequest, v1.DisconnectSubscriberResponse]
+}
+
+// ListTopics calls tkd.eventsservice.v1.IntrospectionService.ListTopics.
+func (c *introspectionServiceClient) ListTopics(ctx context.Context, req *connect_go.Request[v1.ListTopicsRequest]) (*connect_go.Response[v1.ListTopicsResponse], error) {
+	return c.listTopics.CallUnary(ctx, req)
+}
+
+// ListSubscribers calls tkd.eventsservice.v1.IntrospectionService.ListSubscribers.
+func (c *introspectionServiceClient) ListSubscribers(ctx context.Context, req *connect_go.Request[v1.ListSubscribersRequest]) (*connect_go.Response[v1.ListSubscribersResponse], error) {
+	return c.listSubscribers.CallUnary(ctx, req)
+}
+
+// DisconnectSubscriber calls tkd.eventsservice.v1.IntrospectionService.DisconnectSubscriber.
+func (c *introspectionServiceClient) DisconnectSubscriber(ctx context.Context, req *connect_go.Request[v1.DisconnectSubscriberRequest]) (*connect_go.Response[v1.DisconnectSubscriberResponse], error) {
+	return c.disconnectSubscriber.CallUnary(ctx, req)
+}
+
+// IntrospectionServiceHandler is an implementation of the tkd.eventsservice.v1.IntrospectionService
+// service.
+type IntrospectionServiceHandler interface {
+	// ListTopics returns all MQTT topics the events-service is subscribed to.
+	ListTopics(context.Context, *connect_go.Request[v1.ListTopicsRequest]) (*connect_go.Response[v1.ListTopicsResponse], error)
+	// ListSubscribers returns all clients that are currently subscribed.
+	ListSubscribers(context.Context, *connect_go.Request[v1.ListSubscribersRequest]) (*connect_go.Response[v1.ListSubscribersResponse], error)
+	// DisconnectSubscriber forcibly ends the subscription of a client.
+	DisconnectSubscriber(context.Context, *connect_go.Request[v1.DisconnectSubscriberRequest]) (*connect_go.Response[v1.DisconnectSubscriberResponse], error)
+}
+
+// NewIntrospectionServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewIntrospectionServiceHandler(svc IntrospectionServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	introspectionServiceListTopicsHandler := connect_go.NewUnaryHandler(
+		IntrospectionServiceListTopicsProcedure,
+		svc.ListTopics,
+		opts...,
+	)
+	introspectionServiceListSubscribersHandler := connect_go.NewUnaryHandler(
+		IntrospectionServiceListSubscribersProcedure,
+		svc.ListSubscribers,
+		opts...,
+	)
+	introspectionServiceDisconnectSubscriberHandler := connect_go.NewUnaryHandler(
+		IntrospectionServiceDisconnectSubscriberProcedure,
+		svc.DisconnectSubscriber,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.IntrospectionService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case IntrospectionServiceListTopicsProcedure:
+			introspectionServiceListTopicsHandler.ServeHTTP(w, r)
+		case IntrospectionServiceListSubscribersProcedure:
+			introspectionServiceListSubscribersHandler.ServeHTTP(w, r)
+		case IntrospectionServiceDisconnectSubscriberProcedure:
+			introspectionServiceDisconnectSubscriberHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedIntrospectionServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedIntrospectionServiceHandler struct{}
+
+func (UnimplementedIntrospectionServiceHandler) ListTopics(context.Context, *connect_go.Request[v1.ListTopicsRequest]) (*connect_go.Response[v1.ListTopicsResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.IntrospectionService.ListTopics is not implemented"))
+}
+
+func (UnimplementedIntrospectionServiceHandler) ListSubscribers(context.Context, *connect_go.Request[v1.ListSubscribersRequest]) (*connect_go.Response[v1.ListSubscribersResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.IntrospectionService.ListSubscribers is not implemented"))
+}
+
+func (UnimplementedIntrospectionServiceHandler) DisconnectSubscriber(context.Context, *connect_go.Request[v1.DisconnectSubscriberRequest]) (*connect_go.Response[v1.DisconnectSubscriberResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.IntrospectionService.DisconnectSubscriber is not implemented"))
+}
@@ -0,0 +1,214 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/eventsservice/v1/retained.proto
+
+package eventsservicev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// RetainedServiceName is the fully-qualified name of the RetainedService service.
+	RetainedServiceName = "tkd.eventsservice.v1.RetainedService"
+	// RetainedAdminServiceName is the fully-qualified name of the RetainedAdminService service.
+	RetainedAdminServiceName = "tkd.eventsservice.v1.RetainedAdminService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// RetainedServiceGetRetainedProcedure is the fully-qualified name of the RetainedService's
+	// GetRetained RPC.
+	RetainedServiceGetRetainedProcedure = "/tkd.eventsservice.v1.RetainedService/GetRetained"
+	// RetainedAdminServiceListRetainedProcedure is the fully-qualified name of the
+	// RetainedAdminService's ListRetained RPC.
+	RetainedAdminServiceListRetainedProcedure = "/tkd.eventsservice.v1.RetainedAdminService/ListRetained"
+	// RetainedAdminServiceClearRetainedProcedure is the fully-qualified name of the
+	// RetainedAdminService's ClearRetained RPC.
+	RetainedAdminServiceClearRetainedProcedure = "/tkd.eventsservice.v1.RetainedAdminService/ClearRetained"
+)
+
+// RetainedServiceClient is a client for the tkd.eventsservice.v1.RetainedService service.
+type RetainedServiceClient interface {
+	// GetRetained returns the current retained event for each requested
+	// type.
+	GetRetained(context.Context, *connect_go.Request[v1.GetRetainedRequest]) (*connect_go.Response[v1.GetRetainedResponse], error)
+}
+
+// NewRetainedServiceClient constructs a client for the tkd.eventsservice.v1.RetainedService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewRetainedServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) RetainedServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &retainedServiceClient{
+		getRetained: connect_go.NewClient[v1.GetRetainedRequest, v1.GetRetainedResponse](
+			httpClient,
+			baseURL+RetainedServiceGetRetainedProcedure,
+			opts...,
+		),
+	}
+}
+
+// retainedServiceClient implements RetainedServiceClient.
+type retainedServiceClient struct {
+	getRetained *connect_go.Client[v1.GetRetainedRequest, v1.GetRetainedResponse]
+}
+
+// GetRetained calls tkd.eventsservice.v1.RetainedService.GetRetained.
+func (c *retainedServiceClient) GetRetained(ctx context.Context, req *connect_go.Request[v1.GetRetainedRequest]) (*connect_go.Response[v1.GetRetainedResponse], error) {
+	return c.getRetained.CallUnary(ctx, req)
+}
+
+// RetainedServiceHandler is an implementation of the tkd.eventsservice.v1.RetainedService service.
+type RetainedServiceHandler interface {
+	// GetRetained returns the current retained event for each requested
+	// type.
+	GetRetained(context.Context, *connect_go.Request[v1.GetRetainedRequest]) (*connect_go.Response[v1.GetRetainedResponse], error)
+}
+
+// NewRetainedServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewRetainedServiceHandler(svc RetainedServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	retainedServiceGetRetainedHandler := connect_go.NewUnaryHandler(
+		RetainedServiceGetRetainedProcedure,
+		svc.GetRetained,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.RetainedService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case RetainedServiceGetRetainedProcedure:
+			retainedServiceGetRetainedHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedRetainedServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedRetainedServiceHandler struct{}
+
+func (UnimplementedRetainedServiceHandler) GetRetained(context.Context, *connect_go.Request[v1.GetRetainedRequest]) (*connect_go.Response[v1.GetRetainedResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.RetainedService.GetRetained is not implemented"))
+}
+
+// RetainedAdminServiceClient is a client for the tkd.eventsservice.v1.RetainedAdminService service.
+type RetainedAdminServiceClient interface {
+	// ListRetained returns the retained events of all types.
+	ListRetained(context.Context, *connect_go.Request[v1.ListRetainedRequest]) (*connect_go.Response[v1.ListRetainedResponse], error)
+	// ClearRetained removes the retained event of a type by publishing an
+	// empty retained message.
+	ClearRetained(context.Context, *connect_go.Request[v1.ClearRetainedRequest]) (*connect_go.Response[v1.ClearRetainedResponse], error)
+}
+
+// NewRetainedAdminServiceClient constructs a client for the
+// tkd.eventsservice.v1.RetainedAdminService service. By default, it uses the Connect protocol with
+// the binary Protobuf Codec, asks for gzipped responses, and sends uncompressed requests. To use
+// the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewRetainedAdminServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) RetainedAdminServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &retainedAdminServiceClient{
+		listRetained: connect_go.NewClient[v1.ListRetainedRequest, v1.ListRetainedResponse](
+			httpClient,
+			baseURL+RetainedAdminServiceListRetainedProcedure,
+			opts...,
+		),
+		clearRetained: connect_go.NewClient[v1.ClearRetainedRequest, v1.ClearRetainedResponse](
+			httpClient,
+			baseURL+RetainedAdminServiceClearRetainedProcedure,
+			opts...,
+		),
+	}
+}
+
+// retainedAdminServiceClient implements RetainedAdminServiceClient.
+type retainedAdminServiceClient struct {
+	listRetained  *connect_go.Client[v1.ListRetainedRequest, v1.ListRetainedResponse]
+	clearRetained *connect_go.Client[v1.ClearRetainedRequest, v1.ClearRetainedResponse]
+}
+
+// ListRetained calls tkd.eventsservice.v1.RetainedAdminService.ListRetained.
+func (c *retainedAdminServiceClient) ListRetained(ctx context.Context, req *connect_go.Request[v1.ListRetainedRequest]) (*connect_go.Response[v1.ListRetainedResponse], error) {
+	return c.listRetained.CallUnary(ctx, req)
+}
+
+// ClearRetained calls tkd.eventsservice.v1.RetainedAdminService.ClearRetained.
+func (c *retainedAdminServiceClient) ClearRetained(ctx context.Context, req *connect_go.Request[v1.ClearRetainedRequest]) (*connect_go.Response[v1.ClearRetainedResponse], error) {
+	return c.clearRetained.CallUnary(ctx, req)
+}
+
+// RetainedAdminServiceHandler is an implementation of the tkd.eventsservice.v1.RetainedAdminService
+// service.
+type RetainedAdminServiceHandler interface {
+	// ListRetained returns the retained events of all types.
+	ListRetained(context.Context, *connect_go.Request[v1.ListRetainedRequest]) (*connect_go.Response[v1.ListRetainedResponse], error)
+	// ClearRetained removes the retained event of a type by publishing an
+	// empty retained message.
+	ClearRetained(context.Context, *connect_go.Request[v1.ClearRetainedRequest]) (*connect_go.Response[v1.ClearRetainedResponse], error)
+}
+
+// NewRetainedAdminServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewRetainedAdminServiceHandler(svc RetainedAdminServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	retainedAdminServiceListRetainedHandler := connect_go.NewUnaryHandler(
+		RetainedAdminServiceListRetainedProcedure,
+		svc.ListRetained,
+		opts...,
+	)
+	retainedAdminServiceClearRetainedHandler := connect_go.NewUnaryHandler(
+		RetainedAdminServiceClearRetainedProcedure,
+		svc.ClearRetained,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.RetainedAdminService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case RetainedAdminServiceListRetainedProcedure:
+			retainedAdminServiceListRetainedHandler.ServeHTTP(w, r)
+		case RetainedAdminServiceClearRetainedProcedure:
+			retainedAdminServiceClearRetainedHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedRetainedAdminServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedRetainedAdminServiceHandler struct{}
+
+func (UnimplementedRetainedAdminServiceHandler) ListRetained(context.Context, *connect_go.Request[v1.ListRetainedRequest]) (*connect_go.Response[v1.ListRetainedResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.RetainedAdminService.ListRetained is not implemented"))
+}
+
+func (UnimplementedRetainedAdminServiceHandler) ClearRetained(context.Context, *connect_go.Request[v1.ClearRetainedRequest]) (*connect_go.Response[v1.ClearRetainedResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.RetainedAdminService.ClearRetained is not implemented"))
+}
@@ -0,0 +1,194 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/eventsservice/v1/storage.proto
+
+package eventsservicev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// StorageServiceName is the fully-qualified name of the StorageService service.
+	StorageServiceName = "tkd.eventsservice.v1.StorageService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// StorageServiceListStorageNamespacesProcedure is the fully-qualified name of the StorageService's
+	// ListStorageNamespaces RPC.
+	StorageServiceListStorageNamespacesProcedure = "/tkd.eventsservice.v1.StorageService/ListStorageNamespaces"
+	// StorageServiceListStorageEntriesProcedure is the fully-qualified name of the StorageService's
+	// ListStorageEntries RPC.
+	StorageServiceListStorageEntriesProcedure = "/tkd.eventsservice.v1.StorageService/ListStorageEntries"
+	// StorageServiceDeleteStorageEntryProcedure is the fully-qualified name of the StorageService's
+	// DeleteStorageEntry RPC.
+	StorageServiceDeleteStorageEntryProcedure = "/tkd.eventsservice.v1.StorageService/DeleteStorageEntry"
+	// StorageServiceWipeStorageProcedure is the fully-qualified name of the StorageService's
+	// WipeStorage RPC.
+	StorageServiceWipeStorageProcedure = "/tkd.eventsservice.v1.StorageService/WipeStorage"
+)
+
+// StorageServiceClient is a client for the tkd.eventsservice.v1.StorageService service.
+type StorageServiceClient interface {
+	// ListStorageNamespaces returns all bundles that have stored data.
+	ListStorageNamespaces(context.Context, *connect_go.Request[v1.ListStorageNamespacesRequest]) (*connect_go.Response[v1.ListStorageNamespacesResponse], error)
+	// ListStorageEntries returns the entries of a bundle ordered by key.
+	ListStorageEntries(context.Context, *connect_go.Request[v1.ListStorageEntriesRequest]) (*connect_go.Response[v1.ListStorageEntriesResponse], error)
+	// DeleteStorageEntry removes a single entry.
+	DeleteStorageEntry(context.Context, *connect_go.Request[v1.DeleteStorageEntryRequest]) (*connect_go.Response[v1.DeleteStorageEntryResponse], error)
+	// WipeStorage removes all data of a bundle.
+	WipeStorage(context.Context, *connect_go.Request[v1.WipeStorageRequest]) (*connect_go.Response[v1.WipeStorageResponse], error)
+}
+
+// NewStorageServiceClient constructs a client for the tkd.eventsservice.v1.StorageService service.
+// By default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewStorageServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) StorageServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &storageServiceClient{
+		listStorageNamespaces: connect_go.NewClient[v1.ListStorageNamespacesRequest, v1.ListStorageNamespacesResponse](
+			httpClient,
+			baseURL+StorageServiceListStorageNamespacesProcedure,
+			opts...,
+		),
+		listStorageEntries: connect_go.NewClient[v1.ListStorageEntriesRequest, v1.ListStorageEntriesResponse](
+			httpClient,
+			baseURL+StorageServiceListStorageEntriesProcedure,
+			opts...,
+		),
+		deleteStorageEntry: connect_go.NewClient[v1.DeleteStorageEntryRequest, v1.DeleteStorageEntryResponse](
+			httpClient,
+			baseURL+StorageServiceDeleteStorageEntryProcedure,
+			opts...,
+		),
+		wipeStorage: connect_go.NewClient[v1.WipeStorageRequest, v1.WipeStorageResponse](
+			httpClient,
+			baseURL+StorageServiceWipeStorageProcedure,
+			opts...,
+		),
+	}
+}
+
+// storageServiceClient implements StorageServiceClient.
+type storageServiceClient struct {
+	listStorageNamespaces *connect_go.Client[v1.ListStorageNamespacesRequest, v1.ListStorageNamespacesResponse]
+	listStorageEntries    *connect_go.Client[v1.ListStorageEntriesRequest, v1.ListStorageEntriesResponse]
+	deleteStorageEntry    *connect_go.Client[v1.DeleteStorageEntryRequest, v1.DeleteStorageEntryResponse]
+	wipeStorage           *connect_go.Client[v1.WipeStorageRequest, v1.WipeStorageResponse]
+}
+
+// ListStorageNamespaces calls tkd.eventsservice.v1.StorageService.ListStorageNamespaces.
+func (c *storageServiceClient) ListStorageNamespaces(ctx context.Context, req *connect_go.Request[v1.ListStorageNamespacesRequest]) (*connect_go.Response[v1.ListStorageNamespacesResponse], error) {
+	return c.listStorageNamespaces.CallUnary(ctx, req)
+}
+
+// ListStorageEntries calls tkd.eventsservice.v1.StorageService.ListStorageEntries.
+func (c *storageServiceClient) ListStorageEntries(ctx context.Context, req *connect_go.Request[v1.ListStorageEntriesRequest]) (*connect_go.Response[v1.ListStorageEntriesResponse], error) {
+	return c.listStorageEntries.CallUnary(ctx, req)
+}
+
+// DeleteStorageEntry calls tkd.eventsservice.v1.StorageService.DeleteStorageEntry.
+func (c *storageServiceClient) DeleteStorageEntry(ctx context.Context, req *connect_go.Request[v1.DeleteStorageEntryRequest]) (*connect_go.Response[v1.DeleteStorageEntryResponse], error) {
+	return c.deleteStorageEntry.CallUnary(ctx, req)
+}
+
+// WipeStorage calls tkd.eventsservice.v1.StorageService.WipeStorage.
+func (c *storageServiceClient) WipeStorage(ctx context.Context, req *connect_go.Request[v1.WipeStorageRequest]) (*connect_go.Response[v1.WipeStorageResponse], error) {
+	return c.wipeStorage.CallUnary(ctx, req)
+}
+
+// StorageServiceHandler is an implementation of the tkd.eventsservice.v1.StorageService service.
+type StorageServiceHandler interface {
+	// ListStorageNamespaces returns all bundles that have stored data.
+	ListStorageNamespaces(context.Context, *connect_go.Request[v1.ListStorageNamespacesRequest]) (*connect_go.Response[v1.ListStorageNamespacesResponse], error)
+	// ListStorageEntries returns the entries of a bundle ordered by key.
+	ListStorageEntries(context.Context, *connect_go.Request[v1.ListStorageEntriesRequest]) (*connect_go.Response[v1.ListStorageEntriesResponse], error)
+	// DeleteStorageEntry removes a single entry.
+	DeleteStorageEntry(context.Context, *connect_go.Request[v1.DeleteStorageEntryRequest]) (*connect_go.Response[v1.DeleteStorageEntryResponse], error)
+	// WipeStorage removes all data of a bundle.
+	WipeStorage(context.Context, *connect_go.Request[v1.WipeStorageRequest]) (*connect_go.Response[v1.WipeStorageResponse], error)
+}
+
+// NewStorageServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewStorageServiceHandler(svc StorageServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	storageServiceListStorageNamespacesHandler := connect_go.NewUnaryHandler(
+		StorageServiceListStorageNamespacesProcedure,
+		svc.ListStorageNamespaces,
+		opts...,
+	)
+	storageServiceListStorageEntriesHandler := connect_go.NewUnaryHandler(
+		StorageServiceListStorageEntriesProcedure,
+		svc.ListStorageEntries,
+		opts...,
+	)
+	storageServiceDeleteStorageEntryHandler := connect_go.NewUnaryHandler(
+		StorageServiceDeleteStorageEntryProcedure,
+		svc.DeleteStorageEntry,
+		opts...,
+	)
+	storageServiceWipeStorageHandler := connect_go.NewUnaryHandler(
+		StorageServiceWipeStorageProcedure,
+		svc.WipeStorage,
+		opts...,
+	)
+	return "/tkd.eventsservice.v1.StorageService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case StorageServiceListStorageNamespacesProcedure:
+			storageServiceListStorageNamespacesHandler.ServeHTTP(w, r)
+		case StorageServiceListStorageEntriesProcedure:
+			storageServiceListStorageEntriesHandler.ServeHTTP(w, r)
+		case StorageServiceDeleteStorageEntryProcedure:
+			storageServiceDeleteStorageEntryHandler.ServeHTTP(w, r)
+		case StorageServiceWipeStorageProcedure:
+			storageServiceWipeStorageHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedStorageServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedStorageServiceHandler struct{}
+
+func (UnimplementedStorageServiceHandler) ListStorageNamespaces(context.Context, *connect_go.Request[v1.ListStorageNamespacesRequest]) (*connect_go.Response[v1.ListStorageNamespacesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.StorageService.ListStorageNamespaces is not implemented"))
+}
+
+func (UnimplementedStorageServiceHandler) ListStorageEntries(context.Context, *connect_go.Request[v1.ListStorageEntriesRequest]) (*connect_go.Response[v1.ListStorageEntriesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.StorageService.ListStorageEntries is not implemented"))
+}
+
+func (UnimplementedStorageServiceHandler) DeleteStorageEntry(context.Context, *connect_go.Request[v1.DeleteStorageEntryRequest]) (*connect_go.Response[v1.DeleteStorageEntryResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.StorageService.DeleteStorageEntry is not implemented"))
+}
+
+func (UnimplementedStorageServiceHandler) WipeStorage(context.Context, *connect_go.Request[v1.WipeStorageRequest]) (*connect_go.Response[v1.WipeStorageResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.eventsservice.v1.StorageService.WipeStorage is not implemented"))
+}
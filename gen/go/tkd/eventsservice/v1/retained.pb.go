@@ -0,0 +1,493 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: tkd/eventsservice/v1/retained.proto
+
+package eventsservicev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RetainedEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// TypeUrl is the event type.
+	TypeUrl string `protobuf:"bytes,1,opt,name=type_url,json=typeUrl,proto3" json:"type_url,omitempty"`
+	// Event holds the current retained event.
+	Event *anypb.Any `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	// Key is the value of the configured key field for event types that
+	// retain their state per entity. Empty for all other types.
+	Key           string `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetainedEvent) Reset() {
+	*x = RetainedEvent{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetainedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetainedEvent) ProtoMessage() {}
+
+func (x *RetainedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetainedEvent.ProtoReflect.Descriptor instead.
+func (*RetainedEvent) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RetainedEvent) GetTypeUrl() string {
+	if x != nil {
+		return x.TypeUrl
+	}
+	return ""
+}
+
+func (x *RetainedEvent) GetEvent() *anypb.Any {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *RetainedEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetRetainedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// TypeUrls is a list of event types to return the retained event for.
+	TypeUrls      []string `protobuf:"bytes,1,rep,name=type_urls,json=typeUrls,proto3" json:"type_urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRetainedRequest) Reset() {
+	*x = GetRetainedRequest{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRetainedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRetainedRequest) ProtoMessage() {}
+
+func (x *GetRetainedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRetainedRequest.ProtoReflect.Descriptor instead.
+func (*GetRetainedRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRetainedRequest) GetTypeUrls() []string {
+	if x != nil {
+		return x.TypeUrls
+	}
+	return nil
+}
+
+type GetRetainedResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Events holds the retained events of the requested types in the order
+	// of the request. Types without a retained event are omitted while types
+	// that retain their state per entity may have multiple entries.
+	Events        []*RetainedEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRetainedResponse) Reset() {
+	*x = GetRetainedResponse{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRetainedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRetainedResponse) ProtoMessage() {}
+
+func (x *GetRetainedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRetainedResponse.ProtoReflect.Descriptor instead.
+func (*GetRetainedResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRetainedResponse) GetEvents() []*RetainedEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ListRetainedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRetainedRequest) Reset() {
+	*x = ListRetainedRequest{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRetainedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRetainedRequest) ProtoMessage() {}
+
+func (x *ListRetainedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRetainedRequest.ProtoReflect.Descriptor instead.
+func (*ListRetainedRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{3}
+}
+
+type ListRetainedResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Events holds the retained events of all types, ordered by type URL
+	// and key.
+	Events        []*RetainedEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRetainedResponse) Reset() {
+	*x = ListRetainedResponse{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRetainedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRetainedResponse) ProtoMessage() {}
+
+func (x *ListRetainedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRetainedResponse.ProtoReflect.Descriptor instead.
+func (*ListRetainedResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListRetainedResponse) GetEvents() []*RetainedEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ClearRetainedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// TypeUrl is the event type whose retained event should be cleared.
+	TypeUrl string `protobuf:"bytes,1,opt,name=type_url,json=typeUrl,proto3" json:"type_url,omitempty"`
+	// Key selects the entity for event types that retain their state per
+	// entity.
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearRetainedRequest) Reset() {
+	*x = ClearRetainedRequest{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearRetainedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearRetainedRequest) ProtoMessage() {}
+
+func (x *ClearRetainedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearRetainedRequest.ProtoReflect.Descriptor instead.
+func (*ClearRetainedRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ClearRetainedRequest) GetTypeUrl() string {
+	if x != nil {
+		return x.TypeUrl
+	}
+	return ""
+}
+
+func (x *ClearRetainedRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ClearRetainedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearRetainedResponse) Reset() {
+	*x = ClearRetainedResponse{}
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearRetainedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearRetainedResponse) ProtoMessage() {}
+
+func (x *ClearRetainedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_eventsservice_v1_retained_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearRetainedResponse.ProtoReflect.Descriptor instead.
+func (*ClearRetainedResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_eventsservice_v1_retained_proto_rawDescGZIP(), []int{6}
+}
+
+var File_tkd_eventsservice_v1_retained_proto protoreflect.FileDescriptor
+
+var file_tkd_eventsservice_v1_retained_proto_rawDesc = string([]byte{
+	0x0a, 0x23, 0x74, 0x6b, 0x64, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x72, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x74, 0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x19, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x68, 0x0a, 0x0d, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x79, 0x70, 0x65, 0x5f,
+	0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x79, 0x70, 0x65, 0x55,
+	0x72, 0x6c, 0x12, 0x2a, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x22, 0x31, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x75,
+	0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x55,
+	0x72, 0x6c, 0x73, 0x22, 0x52, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x6b, 0x64,
+	0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x15, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x53,
+	0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x22, 0x43, 0x0a, 0x14, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x52, 0x65, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74,
+	0x79, 0x70, 0x65, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74,
+	0x79, 0x70, 0x65, 0x55, 0x72, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x17, 0x0a, 0x15, 0x43, 0x6c, 0x65, 0x61,
+	0x72, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x32, 0x75, 0x0a, 0x0f, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x62, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x64, 0x12, 0x28, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e,
+	0x74, 0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xe7, 0x01, 0x0a, 0x14, 0x52, 0x65, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x64, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x65, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65,
+	0x64, 0x12, 0x29, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x74,
+	0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x68, 0x0a, 0x0d, 0x43, 0x6c, 0x65, 0x61,
+	0x72, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x12, 0x2a, 0x2e, 0x74, 0x6b, 0x64, 0x2e,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x65,
+	0x61, 0x72, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x42, 0xf8, 0x01, 0x0a, 0x18, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x6b, 0x64, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x42,
+	0x0d, 0x52, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01,
+	0x5a, 0x5b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x69, 0x65,
+	0x72, 0x6b, 0x6c, 0x69, 0x6e, 0x69, 0x6b, 0x2d, 0x64, 0x6f, 0x62, 0x65, 0x72, 0x73, 0x62, 0x65,
+	0x72, 0x67, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x74, 0x6b, 0x64, 0x2f, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x3b, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x76, 0x31, 0xa2, 0x02, 0x03,
+	0x54, 0x45, 0x58, 0xaa, 0x02, 0x14, 0x54, 0x6b, 0x64, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x14, 0x54, 0x6b, 0x64,
+	0x5c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5c, 0x56,
+	0x31, 0xe2, 0x02, 0x20, 0x54, 0x6b, 0x64, 0x5c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x16, 0x54, 0x6b, 0x64, 0x3a, 0x3a, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_tkd_eventsservice_v1_retained_proto_rawDescOnce sync.Once
+	file_tkd_eventsservice_v1_retained_proto_rawDescData []byte
+)
+
+func file_tkd_eventsservice_v1_retained_proto_rawDescGZIP() []byte {
+	file_tkd_eventsservice_v1_retained_proto_rawDescOnce.Do(func() {
+		file_tkd_eventsservice_v1_retained_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_eventsservice_v1_retained_proto_rawDesc), len(file_tkd_eventsservice_v1_retained_proto_rawDesc)))
+	})
+	return file_tkd_eventsservice_v1_retained_proto_rawDescData
+}
+
+var file_tkd_eventsservice_v1_retained_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_tkd_eventsservice_v1_retained_proto_goTypes = []any{
+	(*RetainedEvent)(nil),         // 0: tkd.eventsservice.v1.RetainedEvent
+	(*GetRetainedRequest)(nil),    // 1: tkd.eventsservice.v1.GetRetainedRequest
+	(*GetRetainedResponse)(nil),   // 2: tkd.eventsservice.v1.GetRetainedResponse
+	(*ListRetainedRequest)(nil),   // 3: tkd.eventsservice.v1.ListRetainedRequest
+	(*ListRetainedResponse)(nil),  // 4: tkd.eventsservice.v1.ListRetainedResponse
+	(*ClearRetainedRequest)(nil),  // 5: tkd.eventsservice.v1.ClearRetainedRequest
+	(*ClearRetainedResponse)(nil), // 6: tkd.eventsservice.v1.ClearRetainedResponse
+	(*anypb.Any)(nil),             // 7: google.protobuf.Any
+}
+var file_tkd_eventsservice_v1_retained_proto_depIdxs = []int32{
+	7, // 0: tkd.eventsservice.v1.RetainedEvent.event:type_name -> google.protobuf.Any
+	0, // 1: tkd.eventsservice.v1.GetRetainedResponse.events:type_name -> tkd.eventsservice.v1.RetainedEvent
+	0, // 2: tkd.eventsservice.v1.ListRetainedResponse.events:type_name -> tkd.eventsservice.v1.RetainedEvent
+	1, // 3: tkd.eventsservice.v1.RetainedService.GetRetained:input_type -> tkd.eventsservice.v1.GetRetainedRequest
+	3, // 4: tkd.eventsservice.v1.RetainedAdminService.ListRetained:input_type -> tkd.eventsservice.v1.ListRetainedRequest
+	5, // 5: tkd.eventsservice.v1.RetainedAdminService.ClearRetained:input_type -> tkd.eventsservice.v1.ClearRetainedRequest
+	2, // 6: tkd.eventsservice.v1.RetainedService.GetRetained:output_type -> tkd.eventsservice.v1.GetRetainedResponse
+	4, // 7: tkd.eventsservice.v1.RetainedAdminService.ListRetained:output_type -> tkd.eventsservice.v1.ListRetainedResponse
+	6, // 8: tkd.eventsservice.v1.RetainedAdminService.ClearRetained:output_type -> tkd.eventsservice.v1.ClearRetainedResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_tkd_eventsservice_v1_retained_proto_init() }
+func file_tkd_eventsservice_v1_retained_proto_init() {
+	if File_tkd_eventsservice_v1_retained_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_eventsservice_v1_retained_proto_rawDesc), len(file_tkd_eventsservice_v1_retained_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_tkd_eventsservice_v1_retained_proto_goTypes,
+		DependencyIndexes: file_tkd_eventsservice_v1_retained_proto_depIdxs,
+		MessageInfos:      file_tkd_eventsservice_v1_retained_proto_msgTypes,
+	}.Build()
+	File_tkd_eventsservice_v1_retained_proto = out.File
+	file_tkd_eventsservice_v1_retained_proto_goTypes = nil
+	file_tkd_eventsservice_v1_retained_proto_depIdxs = nil
+}
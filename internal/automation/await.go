@@ -0,0 +1,163 @@
+package automation
+
+import (
+	"context"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/common"
+)
+
+// RejectionError is returned for async handlers whose promise has been
+// rejected.
+type RejectionError struct {
+	// Reason is the string representation of the rejection reason.
+	Reason string
+
+	// Stack holds the JavaScript stack trace if the promise has been
+	// rejected with an Error.
+	Stack string
+
+	cause error
+}
+
+func (e *RejectionError) Error() string {
+	return e.Reason
+}
+
+func (e *RejectionError) Unwrap() error {
+	return e.cause
+}
+
+// rejectionError returns the error for a promise that has been rejected with
+// reason. It must be called on the event loop.
+func rejectionError(r *goja.Runtime, reason goja.Value) error {
+	err := &RejectionError{
+		Reason: "promise rejected",
+	}
+
+	if common.IsNullish(reason) {
+		return err
+	}
+
+	err.Reason = reason.String()
+
+	if obj, ok := reason.(*goja.Object); ok {
+		if stack := obj.Get("stack"); !common.IsNullish(stack) {
+			err.Stack = stack.String()
+		}
+
+		if cause, ok := obj.Export().(error); ok {
+			err.cause = cause
+		}
+	}
+
+	return err
+}
+
+// await calls done with the result of value. If value is a promise, done is
+// called once the promise is settled with either the resolved value or the
+// rejection. If the promise is still pending at deadline, done is called with
+// ErrHandlerTimeout; the promise is not cancelled. A zero deadline waits
+// forever. await and done are called on the event loop.
+func (c *CoreModule) await(r *goja.Runtime, value goja.Value, deadline time.Time, done func(goja.Value, error)) {
+	if value == nil {
+		done(goja.Undefined(), nil)
+		return
+	}
+
+	if _, ok := value.Export().(*goja.Promise); !ok {
+		done(value, nil)
+		return
+	}
+
+	promise := value.ToObject(r)
+
+	then, ok := goja.AssertFunction(promise.Get("then"))
+	if !ok {
+		done(value, nil)
+		return
+	}
+
+	var (
+		settled bool
+		timer   *time.Timer
+	)
+
+	settle := func(value goja.Value, err error) {
+		if settled {
+			return
+		}
+		settled = true
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		done(value, err)
+	}
+
+	// handling the rejection here also prevents it from being reported as
+	// unhandled.
+	if _, err := then(promise,
+		r.ToValue(func(call goja.FunctionCall) goja.Value {
+			settle(call.Argument(0), nil)
+			return goja.Undefined()
+		}),
+		r.ToValue(func(call goja.FunctionCall) goja.Value {
+			settle(nil, rejectionError(r, call.Argument(0)))
+			return goja.Undefined()
+		}),
+	); err != nil {
+		settle(nil, err)
+		return
+	}
+
+	if !deadline.IsZero() && !settled {
+		timer = time.AfterFunc(time.Until(deadline), func() {
+			c.engine.loop.RunOnLoop(func(*goja.Runtime) {
+				settle(nil, ErrHandlerTimeout)
+			})
+		})
+	}
+}
+
+// contextTracker implements goja.AsyncContextTracker. It captures the context
+// of the handler that registers a promise reaction and restores it while the
+// reaction is executing, so code after an await is still part of the
+// handler's trace. Reactions are guarded by the watchdog as well.
+type contextTracker struct {
+	engine  *Engine
+	restore func()
+	stop    func()
+}
+
+func (t *contextTracker) Grab() any {
+	return t.engine.ctx
+}
+
+func (t *contextTracker) Resumed(obj any) {
+	// a reaction that has been interrupted never exits
+	t.Exited()
+
+	ctx, ok := obj.(context.Context)
+	if !ok {
+		ctx = context.Background()
+	}
+
+	t.restore = t.engine.enter(ctx)
+	t.stop = t.engine.core.guard()
+}
+
+func (t *contextTracker) Exited() {
+	// reactions are never nested
+	if t.stop != nil {
+		t.stop()
+		t.stop = nil
+	}
+
+	if t.restore != nil {
+		t.restore()
+		t.restore = nil
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/dop251/goja"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/transpile"
 	"github.com/tierklinik-dobersberg/events-service/internal/config"
 )
 
@@ -40,6 +41,10 @@ type Log struct {
 }
 
 type Bundle struct {
+	// Source holds the path of the directory or archive the bundle has been
+	// discovered from. It is empty if the bundle has been loaded using Load.
+	Source string
+
 	// Path holds the path to the bundle root
 	Path string
 
@@ -53,14 +58,22 @@ type Bundle struct {
 	// License is the SPDX license identifier for the automation bundle.
 	License string
 
-	// ScriptContent holds the content of the main entrypoint file.
+	// ScriptContent holds the content of the main entrypoint file. Prepare
+	// bundles the entry point and its dependencies from disk.
 	ScriptContent string
 
 	AutomationConfig modules.AutomationAnnotation
 
 	lock    sync.Mutex
 	runtime *automation.Engine
-	logs    []Log
+	logs    *LogBuffer
+
+	// unpacked holds the temporary directory an archive has been unpacked
+	// to.
+	unpacked string
+
+	// varsPath overwrites the path of the vars.json file.
+	varsPath string
 }
 
 // Discover discovers all automation bundles at a specified root.
@@ -73,66 +86,110 @@ func Discover(root string) ([]*Bundle, error) {
 	var result []*Bundle
 
 	for _, e := range entries {
-		path := filepath.Join(root, e.Name())
-		if e.IsDir() {
-			b, err := Load(path)
-			if err != nil {
-				slog.Error("failed to load bundle", "error", err, "path", e.Name())
-				continue
-			}
-
-			result = append(result, b)
+		if !isSource(e) {
+			continue
+		}
 
+		b, err := LoadSource(filepath.Join(root, e.Name()))
+		if err != nil {
+			slog.Error("failed to load bundle", "error", err, "path", e.Name())
 			continue
 		}
 
-		ext := filepath.Ext(e.Name())
+		result = append(result, b)
+	}
 
-		var (
-			target string
-		)
+	return result, nil
+}
 
-		var gzip bool
+// isSource reports whether e is a directory or an archive that may contain
+// an automation bundle.
+func isSource(e os.DirEntry) bool {
+	name := e.Name()
 
-		switch ext {
-		case ".zip":
-			target, err = unpackZip(path)
-			if err != nil {
-				slog.Error("failed to unpack zip file", "path", e.Name(), "error", err)
-				continue
-			}
+	// hidden entries are used for temporary files and state
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
 
-		case ".gz":
-			if filepath.Ext(strings.TrimSuffix(e.Name(), ".gz")) != ".tar" {
-				continue
-			}
-			gzip = true
-			fallthrough
+	if e.IsDir() {
+		return true
+	}
 
-		case ".tar":
-			target, err = unpackTar(gzip, path)
-			if err != nil {
-				slog.Error("failedt o unpack tar file", "path", e.Name(), "error", err)
-				continue
-			}
+	return isArchive(name)
+}
 
-		default:
-			continue
+// isArchive reports whether name is the name of a supported bundle archive.
+func isArchive(name string) bool {
+	switch filepath.Ext(name) {
+	case ".zip", ".tar":
+		return true
+	case ".gz":
+		return filepath.Ext(strings.TrimSuffix(name, ".gz")) == ".tar"
+	}
+
+	return false
+}
+
+// LoadSource loads the bundle from path which may either be a directory or
+// a .zip, .tar or .tar.gz archive. Archives are unpacked to a temporary
+// directory that is removed by Bundle.Stop. The vars.json of archives is
+// read from <path>.vars.json.
+func LoadSource(path string) (*Bundle, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		b, err := Load(path)
+		if err != nil {
+			return nil, err
 		}
 
-		if target != "" {
-			b, err := Load(target)
-			if err != nil {
-				slog.Error("failed to load bundle", "error", err, "path", e.Name())
-				continue
-			}
+		b.Source = path
 
-			result = append(result, b)
+		return b, nil
+	}
 
+	var (
+		target string
+		name   = filepath.Base(path)
+	)
+
+	switch filepath.Ext(name) {
+	case ".zip":
+		target, err = unpackZip(path)
+
+	case ".tar":
+		target, err = unpackTar(false, path)
+
+	case ".gz":
+		if filepath.Ext(strings.TrimSuffix(name, ".gz")) != ".tar" {
+			return nil, fmt.Errorf("%w: unsupported archive %q", ErrInvalidBundle, name)
 		}
+
+		target, err = unpackTar(true, path)
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported archive %q", ErrInvalidBundle, name)
 	}
 
-	return result, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %q: %w", name, err)
+	}
+
+	b, err := Load(target)
+	if err != nil {
+		os.RemoveAll(target)
+		return nil, err
+	}
+
+	b.Source = path
+	b.unpacked = target
+	b.varsPath = path + ".vars.json"
+
+	return b, nil
 }
 
 func Load(path string) (*Bundle, error) {
@@ -172,8 +229,11 @@ func Load(path string) (*Bundle, error) {
 
 	// try to find the entrypoint if main is unset
 	if parsed.Main == "" {
-		if fileExists(path, "index.js") {
-			parsed.Main = "index.js"
+		for _, name := range []string{"index.js", "index.ts"} {
+			if fileExists(path, name) {
+				parsed.Main = name
+				break
+			}
 		}
 	} else {
 		if !fileExists(path, parsed.Main) {
@@ -199,6 +259,46 @@ func Load(path string) (*Bundle, error) {
 	return bundle, nil
 }
 
+// Name returns the name of the bundle, which is the path of its source or
+// the bundle root if it has not been discovered.
+func (bundle *Bundle) Name() string {
+	if bundle.Source != "" {
+		return bundle.Source
+	}
+
+	return bundle.Path
+}
+
+// VarsPath returns the path of the vars.json file that holds the parameter
+// values of the bundle.
+func (bundle *Bundle) VarsPath() string {
+	if bundle.varsPath != "" {
+		return bundle.varsPath
+	}
+
+	return filepath.Join(bundle.Path, "vars.json")
+}
+
+// Values returns the parameter values from the vars.json file. It returns
+// nil if the file does not exist.
+func (bundle *Bundle) Values() (map[string]any, error) {
+	content, err := os.ReadFile(bundle.VarsPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read vars.json file: %w", err)
+	}
+
+	var params map[string]any
+	if err := json.Unmarshal(content, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse vars.json file: %w", err)
+	}
+
+	return params, nil
+}
+
 // Runtime returns the bundle's automation runtime. This returns nil until bundle.Prepare()
 // is called once.
 func (bundle *Bundle) Runtime() *automation.Engine {
@@ -208,19 +308,23 @@ func (bundle *Bundle) Runtime() *automation.Engine {
 	return bundle.runtime
 }
 
-func (bundle *Bundle) ReadLogs(minLevel slog.Level) []Log {
+// Logs returns the buffer that holds the bundle's console messages. This
+// returns nil until bundle.Prepare() is called once.
+func (bundle *Bundle) Logs() *LogBuffer {
 	bundle.lock.Lock()
 	defer bundle.lock.Unlock()
 
-	result := make([]Log, 0, len(bundle.logs))
+	return bundle.logs
+}
 
-	for _, l := range bundle.logs {
-		if l.Level >= minLevel {
-			result = append(result, l)
-		}
+// ReadLogs returns all buffered console messages with at least minLevel.
+func (bundle *Bundle) ReadLogs(minLevel slog.Level) []Log {
+	logs := bundle.Logs()
+	if logs == nil {
+		return nil
 	}
 
-	return result
+	return logs.Read(minLevel, time.Time{})
 }
 
 func (bundle *Bundle) Prepare(cfg config.Config, broker automation.Broker, opts ...automation.EngineOption) error {
@@ -231,6 +335,17 @@ func (bundle *Bundle) Prepare(cfg config.Config, broker automation.Broker, opts
 		return ErrBundleRuntimePrepared
 	}
 
+	if bundle.logs == nil {
+		bundle.logs = NewLogBuffer(cfg.AutomationLogSize, cfg.AutomationLogMaxAge)
+	}
+
+	// try to load the vars.json file
+	params, err := bundle.Values()
+	if err != nil {
+		bundle.lock.Unlock()
+		return err
+	}
+
 	// Prepend our own engine options so users defined options may overwrite them
 	opts = append([]automation.EngineOption{
 		automation.WithConsole(bundle),
@@ -238,30 +353,12 @@ func (bundle *Bundle) Prepare(cfg config.Config, broker automation.Broker, opts
 		automation.WithAutomationConfig(bundle.AutomationConfig),
 	}, opts...)
 
-	runtime, err := automation.New(bundle.Path, cfg, broker, opts...)
+	runtime, err := automation.New(bundle.Name(), cfg, broker, opts...)
 	if err != nil {
 		bundle.lock.Unlock()
 		return err
 	}
 
-	// try to load the vars.json file
-	varsPath := filepath.Join(bundle.Path, "vars.json")
-	content, err := os.ReadFile(varsPath)
-
-	var params map[string]any
-	switch {
-	case err == nil:
-		if err := json.Unmarshal(content, &params); err != nil {
-			return fmt.Errorf("failed to parse vars.json file: %w", err)
-		}
-
-	case errors.Is(err, os.ErrNotExist):
-		// nothing to do
-
-	default:
-		return fmt.Errorf("failed to read vars.json file: %w", err)
-	}
-
 	bundle.runtime = runtime
 
 	bundle.lock.Unlock()
@@ -283,25 +380,56 @@ func (bundle *Bundle) Prepare(cfg config.Config, broker automation.Broker, opts
 		return nil, nil
 	})
 
+	// the entry point and its dependencies are bundled into a single
+	// script so ES modules and TypeScript sources can be used.
+	// Compile errors are reported to the bundle logs.
+	script, err := transpile.Bundle(filepath.Join(bundle.Path, bundle.Main), bundle.runtime.HasModule)
+	if err != nil {
+		bundle.Error(err.Error())
+
+		return fmt.Errorf("failed to compile entry point: %w", err)
+	}
+
 	// finally, execute the main entry point script
-	if _, err := bundle.runtime.RunScript(bundle.ScriptContent); err != nil {
+	if _, err := bundle.runtime.RunScript(string(script)); err != nil {
 		return fmt.Errorf("failed to evaluate entry point: %w", err)
 	}
 
 	return nil
 }
 
+// Stop stops the bundle's automation runtime, if any, and removes the
+// temporary directory of unpacked archives.
+func (bundle *Bundle) Stop() {
+	bundle.lock.Lock()
+	runtime := bundle.runtime
+	unpacked := bundle.unpacked
+	bundle.unpacked = ""
+	bundle.lock.Unlock()
+
+	if runtime != nil {
+		runtime.Stop()
+	}
+
+	if unpacked != "" {
+		if err := os.RemoveAll(unpacked); err != nil {
+			slog.Error("failed to remove unpacked bundle", "path", unpacked, "error", err)
+		}
+	}
+}
+
 func (bundle *Bundle) internalLog(lvl slog.Level, msg string) {
 	bundle.lock.Lock()
-	defer bundle.lock.Unlock()
+	logs, runtime := bundle.logs, bundle.runtime
+	bundle.lock.Unlock()
 
-	bundle.logs = append(bundle.logs, Log{
+	logs.Append(Log{
 		Time:    time.Now(),
 		Level:   lvl,
 		Message: msg,
 	})
 
-	bundle.runtime.Log().Log(context.TODO(), lvl, msg)
+	runtime.Log().Log(context.TODO(), lvl, msg)
 }
 
 // Log logs an info level message. It implements the console.Printer interface.
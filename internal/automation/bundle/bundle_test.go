@@ -1,10 +1,16 @@
 package bundle
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/dop251/goja"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
 	"github.com/tierklinik-dobersberg/events-service/internal/config"
 )
 
@@ -32,3 +38,180 @@ func TestBundleDiscover(t *testing.T) {
 
 	assert.Len(t, bundles, 4)
 }
+
+func TestLoadTypeScript(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"version": "1.0.0"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greeting.ts"), []byte(`
+		export interface Greeting {
+			name: string
+		}
+
+		export function greet(g: Greeting): string {
+			return "hello " + g.name
+		}
+	`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.ts"), []byte(`
+		import { greet } from "./greeting"
+
+		const name: string = "world";
+		(globalThis as any).result = greet({ name })
+	`), 0o644))
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+	require.Equal(t, "index.ts", bundle.Main)
+
+	require.NoError(t, bundle.Prepare(config.Config{}, nil))
+	defer bundle.Stop()
+
+	result, err := bundle.Runtime().Run(func(r *goja.Runtime) (goja.Value, error) {
+		return r.Get("result"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.String())
+}
+
+func TestLoadTypeScriptCompileError(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"main": "main.ts"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.ts"), []byte("const x: number = ;\n"), 0o644))
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+
+	err = bundle.Prepare(config.Config{}, nil)
+	require.ErrorContains(t, err, "failed to compile entry point")
+	defer bundle.Stop()
+
+	logs := bundle.ReadLogs(slog.LevelError)
+	require.Len(t, logs, 1)
+	require.Contains(t, logs[0].Message, "main.ts:1:19")
+}
+
+type greetingModule struct{}
+
+func (greetingModule) Name() string { return "greeting" }
+
+func (greetingModule) NewModuleInstance(vu modules.VU) (*goja.Object, error) {
+	obj := vu.Runtime().NewObject()
+	obj.Set("prefix", "hello")
+
+	return obj, nil
+}
+
+func TestLoadESModules(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"package.json": `{"version": "1.0.0"}`,
+		"index.js": `
+			import { name } from "legacy"
+			import upper from "modern"
+			import { prefix } from "greeting"
+			import { suffix } from "./lib/suffix.mjs"
+
+			globalThis.result = prefix + " " + upper(name) + suffix
+		`,
+		"lib/suffix.mjs": `export const suffix = "!"`,
+
+		// legacy uses the module field
+		"node_modules/legacy/package.json": `{"main": "cjs.js", "module": "esm.js"}`,
+		"node_modules/legacy/cjs.js":       `throw new Error("main should not be used")`,
+		"node_modules/legacy/esm.js":       `export const name = "world"`,
+
+		// modern uses conditional exports
+		"node_modules/modern/package.json":   `{"exports": {".": {"import": "./dist/index.mjs", "require": "./dist/index.cjs"}}}`,
+		"node_modules/modern/dist/index.mjs": `export default function upper(s) { return s.toUpperCase() }`,
+		"node_modules/modern/dist/index.cjs": `throw new Error("require condition should not be used")`,
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	reg := &modules.Registry{}
+	require.NoError(t, reg.Register(greetingModule{}))
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, bundle.Prepare(config.Config{}, nil, automation.WithModulsRegistry(reg)))
+	defer bundle.Stop()
+
+	result, err := bundle.Runtime().Run(func(r *goja.Runtime) (goja.Value, error) {
+		return r.Get("result"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello WORLD!", result.String())
+}
+
+func TestLoadESModulesUnresolved(t *testing.T) {
+	dir := t.TempDir()
+
+	writeBundle(t, dir, `import missing from "does-not-exist"`)
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+
+	err = bundle.Prepare(config.Config{}, nil)
+	require.ErrorContains(t, err, `Could not resolve "does-not-exist"`)
+	defer bundle.Stop()
+
+	require.Len(t, bundle.ReadLogs(slog.LevelError), 1)
+}
+
+func TestLoadESModuleExports(t *testing.T) {
+	dir := t.TempDir()
+
+	writeBundle(t, dir, `
+		export const answer = 42
+		export default function noop() {}
+
+		globalThis.result = answer
+	`)
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, bundle.Prepare(config.Config{}, nil))
+	defer bundle.Stop()
+
+	result, err := bundle.Runtime().Run(func(r *goja.Runtime) (goja.Value, error) {
+		return r.Get("result"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), result.ToInteger())
+}
+
+func TestLoadEmptyEntryPoint(t *testing.T) {
+	dir := t.TempDir()
+
+	// the import has no side effects and is removed by tree shaking
+	writeBundle(t, dir, `import { unused } from "./lib"`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib.js"), []byte(`export const unused = 1`), 0o644))
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, bundle.Prepare(config.Config{}, nil))
+	bundle.Stop()
+}
+
+func TestLoadSourceMap(t *testing.T) {
+	dir := t.TempDir()
+
+	writeBundle(t, dir, "export const answer = 42\n\nthrow new Error(\"boom\")\n")
+
+	bundle, err := Load(dir)
+	require.NoError(t, err)
+
+	err = bundle.Prepare(config.Config{}, nil)
+	defer bundle.Stop()
+
+	// errors refer to the original source
+	require.ErrorContains(t, err, "index.js:3")
+}
@@ -6,4 +6,9 @@ var (
 	ErrInvalidBundle         = errors.New("invalid bundle")
 	ErrInvalidPackageJSON    = errors.New("invalid package.json")
 	ErrBundleRuntimePrepared = errors.New("bundle runtime has already been prepared")
+	ErrBundleNotFound        = errors.New("bundle not found")
+	ErrBundleExists          = errors.New("bundle already exists")
+	ErrBundleNotLoaded       = errors.New("bundle has not been loaded")
+	ErrInvalidName           = errors.New("invalid bundle name")
+	ErrUnknownParameter      = errors.New("unknown parameter")
 )
@@ -0,0 +1,143 @@
+package bundle
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// watchBuffer is the number of messages buffered for each watcher. Messages
+// are dropped for watchers that fall behind.
+const watchBuffer = 100
+
+// LogBuffer is a ring buffer that holds the latest console messages of a
+// bundle. Messages are evicted once the buffer is full or they are older than
+// the configured maximum age.
+type LogBuffer struct {
+	lock     sync.Mutex
+	entries  []Log
+	start    int
+	count    int
+	maxAge   time.Duration
+	watchers map[chan Log]slog.Level
+	closed   bool
+}
+
+// NewLogBuffer returns a new log buffer that keeps at most size messages that
+// are not older than maxAge. A zero maxAge keeps messages until they are
+// evicted by newer ones.
+func NewLogBuffer(size int, maxAge time.Duration) *LogBuffer {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &LogBuffer{
+		entries:  make([]Log, size),
+		maxAge:   maxAge,
+		watchers: make(map[chan Log]slog.Level),
+	}
+}
+
+// Append adds l to the buffer, evicting the oldest message if the buffer is
+// full, and forwards it to all watchers.
+func (lb *LogBuffer) Append(l Log) {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	if lb.count < len(lb.entries) {
+		lb.entries[(lb.start+lb.count)%len(lb.entries)] = l
+		lb.count++
+	} else {
+		lb.entries[lb.start] = l
+		lb.start = (lb.start + 1) % len(lb.entries)
+	}
+
+	for ch, minLevel := range lb.watchers {
+		if l.Level < minLevel {
+			continue
+		}
+
+		select {
+		case ch <- l:
+		default:
+		}
+	}
+}
+
+// Read returns all messages with at least minLevel that have been logged
+// after since, ordered from oldest to newest.
+func (lb *LogBuffer) Read(minLevel slog.Level, since time.Time) []Log {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	lb.expire()
+
+	result := make([]Log, 0, lb.count)
+	for idx := 0; idx < lb.count; idx++ {
+		l := lb.entries[(lb.start+idx)%len(lb.entries)]
+
+		if l.Level < minLevel || !l.Time.After(since) {
+			continue
+		}
+
+		result = append(result, l)
+	}
+
+	return result
+}
+
+// Watch returns a channel that receives all messages with at least minLevel
+// that are appended from now on. The returned function stops watching and
+// must be called once the caller is done. The channel is closed when the
+// watch is stopped or the buffer is closed.
+func (lb *LogBuffer) Watch(minLevel slog.Level) (<-chan Log, func()) {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	ch := make(chan Log, watchBuffer)
+
+	if lb.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	lb.watchers[ch] = minLevel
+
+	return ch, func() {
+		lb.lock.Lock()
+		defer lb.lock.Unlock()
+
+		if _, ok := lb.watchers[ch]; ok {
+			delete(lb.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close stops all watchers.
+func (lb *LogBuffer) Close() {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	lb.closed = true
+
+	for ch := range lb.watchers {
+		delete(lb.watchers, ch)
+		close(ch)
+	}
+}
+
+// expire evicts messages that are older than lb.maxAge. lb.lock must be held.
+func (lb *LogBuffer) expire() {
+	if lb.maxAge <= 0 {
+		return
+	}
+
+	threshold := time.Now().Add(-lb.maxAge)
+
+	for lb.count > 0 && lb.entries[lb.start].Time.Before(threshold) {
+		lb.entries[lb.start] = Log{}
+		lb.start = (lb.start + 1) % len(lb.entries)
+		lb.count--
+	}
+}
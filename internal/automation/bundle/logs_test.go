@@ -0,0 +1,105 @@
+package bundle
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+)
+
+func messages(logs []Log) []string {
+	result := make([]string, len(logs))
+	for idx, l := range logs {
+		result[idx] = l.Message
+	}
+
+	return result
+}
+
+func TestLogBuffer(t *testing.T) {
+	lb := NewLogBuffer(3, 0)
+	now := time.Now()
+
+	for idx, msg := range []string{"a", "b", "c", "d"} {
+		lb.Append(Log{Time: now.Add(time.Duration(idx) * time.Second), Level: slog.LevelInfo, Message: msg})
+	}
+
+	// the oldest message has been evicted
+	require.Equal(t, []string{"b", "c", "d"}, messages(lb.Read(slog.LevelDebug, time.Time{})))
+	require.Equal(t, []string{"d"}, messages(lb.Read(slog.LevelDebug, now.Add(2*time.Second))))
+
+	lb.Append(Log{Time: now.Add(5 * time.Second), Level: slog.LevelError, Message: "e"})
+	require.Equal(t, []string{"e"}, messages(lb.Read(slog.LevelWarn, time.Time{})))
+}
+
+func TestLogBufferMaxAge(t *testing.T) {
+	lb := NewLogBuffer(10, time.Minute)
+
+	lb.Append(Log{Time: time.Now().Add(-2 * time.Minute), Message: "old"})
+	lb.Append(Log{Time: time.Now(), Message: "new"})
+
+	require.Equal(t, []string{"new"}, messages(lb.Read(slog.LevelDebug, time.Time{})))
+}
+
+func TestLogBufferWatch(t *testing.T) {
+	lb := NewLogBuffer(10, 0)
+
+	logs, stop := lb.Watch(slog.LevelWarn)
+
+	lb.Append(Log{Time: time.Now(), Level: slog.LevelInfo, Message: "info"})
+	lb.Append(Log{Time: time.Now(), Level: slog.LevelWarn, Message: "warn"})
+
+	require.Equal(t, "warn", (<-logs).Message)
+
+	stop()
+
+	_, ok := <-logs
+	require.False(t, ok)
+
+	// closing the buffer ends all watches
+	logs, stop = lb.Watch(slog.LevelDebug)
+	defer stop()
+
+	lb.Close()
+
+	_, ok = <-logs
+	require.False(t, ok)
+}
+
+func TestManagerLogs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "test")
+
+	m := NewManager(root, config.Config{AutomationLogSize: 10}, &fakeBroker{})
+
+	writeBundle(t, dir, `console.log("first")`)
+	m.Sync()
+
+	logs, err := m.Logs("test")
+	require.NoError(t, err)
+	require.Equal(t, []string{"first"}, messages(logs.Read(slog.LevelDebug, time.Time{})))
+
+	watch, stop := logs.Watch(slog.LevelDebug)
+	defer stop()
+
+	// messages are kept across reloads
+	writeBundle(t, dir, `console.error("second")`)
+	m.Sync()
+
+	require.Equal(t, "second", (<-watch).Message)
+	require.Equal(t, []string{"first", "second"}, messages(logs.Read(slog.LevelDebug, time.Time{})))
+
+	// removing the bundle ends all watches
+	require.NoError(t, os.RemoveAll(dir))
+	m.Sync()
+
+	_, ok := <-watch
+	require.False(t, ok)
+
+	_, err = m.Logs("test")
+	require.ErrorIs(t, err, ErrBundleNotFound)
+}
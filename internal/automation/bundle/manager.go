@@ -0,0 +1,890 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/health"
+)
+
+// stateFile is the name of the file in the root directory that holds the
+// names of all disabled bundles.
+const stateFile = ".bundles.json"
+
+// MaxUploadSize is the maximum size of a bundle archive accepted by Upload.
+const MaxUploadSize = 32 << 20
+
+// State describes the state of a bundle managed by a Manager.
+type State int
+
+const (
+	// StateRunning is set if the bundle is loaded and its engine is running.
+	StateRunning State = iota + 1
+
+	// StateDisabled is set if the bundle has been disabled.
+	StateDisabled
+
+	// StateFailed is set if the bundle failed to load.
+	StateFailed
+)
+
+// Info describes a bundle source managed by a Manager.
+type Info struct {
+	// Name is the name of the directory or archive in the root directory.
+	Name string
+
+	// Source is the full path of the bundle source.
+	Source string
+
+	// State is the current state of the bundle.
+	State State
+
+	// Bundle is the loaded bundle. It is nil if the source never loaded
+	// successfully.
+	Bundle *Bundle
+
+	// Err is set if the latest version of the bundle failed to load.
+	Err error
+}
+
+// Manager discovers the automation bundles in a directory, prepares them and
+// keeps them in sync with the content of the directory. Bundles that are
+// added, changed or removed are started, replaced or stopped respectively.
+type Manager struct {
+	root   string
+	cfg    config.Config
+	broker automation.Broker
+	opts   []automation.EngineOption
+
+	// scanLock serializes scans of the root directory and all modifications
+	// of the fields below.
+	scanLock sync.Mutex
+
+	// pending holds the fingerprint of changed sources that have not been
+	// stable for a whole scan interval.
+	pending map[string]string
+
+	// failed holds sources that failed to load so they are only retried
+	// once they change again.
+	failed map[string]failure
+
+	// disabled holds the names of all disabled bundles.
+	disabled map[string]bool
+
+	// logs holds the console messages of each source. They are kept across
+	// reloads and removed together with the source.
+	logs map[string]*LogBuffer
+
+	lock    sync.Mutex
+	bundles map[string]*managedBundle
+
+	failures health.Failures
+
+	log *slog.Logger
+}
+
+type managedBundle struct {
+	bundle      *Bundle
+	fingerprint string
+}
+
+type failure struct {
+	fingerprint string
+	err         error
+}
+
+type managerState struct {
+	Disabled []string `json:"disabled"`
+}
+
+// NewManager returns a new manager for the bundles at root. cfg, broker and
+// opts are used to prepare each bundle.
+func NewManager(root string, cfg config.Config, broker automation.Broker, opts ...automation.EngineOption) *Manager {
+	m := &Manager{
+		root:     root,
+		cfg:      cfg,
+		broker:   broker,
+		opts:     opts,
+		bundles:  make(map[string]*managedBundle),
+		pending:  make(map[string]string),
+		failed:   make(map[string]failure),
+		disabled: make(map[string]bool),
+		logs:     make(map[string]*LogBuffer),
+		log:      slog.Default().With("subsystem", "bundles"),
+	}
+
+	if err := m.loadState(); err != nil {
+		m.log.Error("failed to load bundle state", "error", err)
+	}
+
+	return m
+}
+
+// Sync scans the root directory once and immediately applies all changes.
+func (m *Manager) Sync() {
+	m.scan(false)
+}
+
+// Run blocks until ctx is cancelled and stops all bundles afterwards. If
+// interval is positive, the root directory is scanned periodically. A change
+// is only applied once the source has not been modified for a whole interval
+// so bundles that are still being copied are not loaded.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	defer m.stopAll()
+
+	if interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.scan(true)
+		}
+	}
+}
+
+// Bundles returns all bundles that are currently loaded, ordered by name.
+func (m *Manager) Bundles() []*Bundle {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	result := make([]*Bundle, 0, len(m.bundles))
+	for _, mb := range m.bundles {
+		result = append(result, mb.bundle)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return result
+}
+
+// Engine returns the automation engine of the loaded bundle with the given
+// name or nil.
+func (m *Manager) Engine(name string) *automation.Engine {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, mb := range m.bundles {
+		if mb.bundle.Name() == name {
+			return mb.bundle.Runtime()
+		}
+	}
+
+	return nil
+}
+
+// Check implements health.CheckFunc and fails while the root directory
+// cannot be read or any enabled bundle failed to load without a previous
+// version to keep running.
+func (m *Manager) Check(ctx context.Context) error {
+	return m.failures.Check(ctx)
+}
+
+// List returns all bundles that have been loaded or failed to load, ordered
+// by name.
+func (m *Manager) List() []Info {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	sources := make(map[string]struct{})
+
+	m.lock.Lock()
+	for source := range m.bundles {
+		sources[source] = struct{}{}
+	}
+	m.lock.Unlock()
+
+	for source := range m.failed {
+		sources[source] = struct{}{}
+	}
+
+	result := make([]Info, 0, len(sources))
+	for source := range sources {
+		result = append(result, m.info(filepath.Base(source)))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// Get returns the bundle with the given name.
+func (m *Manager) Get(name string) (Info, error) {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	if _, err := m.source(name); err != nil {
+		return Info{}, err
+	}
+
+	return m.info(name), nil
+}
+
+// Logs returns the buffer that holds the console messages of the bundle with
+// the given name.
+func (m *Manager) Logs(name string) (*LogBuffer, error) {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	source, err := m.source(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.logBuffer(source), nil
+}
+
+// Upload stores the bundle archive content as name in the root directory and
+// loads it. name must end in .zip, .tar or .tar.gz and content must not
+// exceed MaxUploadSize. An existing bundle is only replaced if overwrite is
+// set.
+func (m *Manager) Upload(name string, content []byte, overwrite bool) (Info, error) {
+	if !isArchive(name) || !validName(name) {
+		return Info{}, fmt.Errorf("%w: %q, expected a .zip, .tar or .tar.gz file name", ErrInvalidName, name)
+	}
+
+	if len(content) > MaxUploadSize {
+		return Info{}, fmt.Errorf("%w: archive exceeds %d bytes", ErrInvalidBundle, MaxUploadSize)
+	}
+
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	dest := filepath.Join(m.root, name)
+
+	if _, err := os.Stat(dest); err == nil && !overwrite {
+		return Info{}, fmt.Errorf("%w: %s", ErrBundleExists, name)
+	}
+
+	// write the archive to a hidden file first so it's never picked up by a
+	// scan before it has been validated.
+	tmp := filepath.Join(m.root, ".upload-"+name)
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return Info{}, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	b, err := LoadSource(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return Info{}, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	b.Stop()
+
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return Info{}, fmt.Errorf("failed to store bundle: %w", err)
+	}
+
+	m.log.Info("automation bundle uploaded", "name", name, "version", b.Version)
+
+	m.syncLocked(false)
+
+	return m.info(name), nil
+}
+
+// Enable starts a disabled bundle.
+func (m *Manager) Enable(name string) (Info, error) {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	source, err := m.source(name)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if !m.disabled[name] {
+		return m.info(name), nil
+	}
+
+	delete(m.disabled, name)
+	if err := m.saveState(); err != nil {
+		m.disabled[name] = true
+		return Info{}, err
+	}
+
+	m.lock.Lock()
+	current := m.bundles[source]
+	m.lock.Unlock()
+
+	if current != nil {
+		if runtime := current.bundle.Runtime(); runtime != nil {
+			runtime.Start()
+		} else {
+			// the bundle has only been loaded while it was disabled
+			m.load(source, current.fingerprint, current)
+		}
+	}
+
+	m.log.Info("automation bundle enabled", "name", name)
+
+	// pick up changes while the bundle was disabled
+	m.syncLocked(false)
+
+	return m.info(name), nil
+}
+
+// Disable stops a bundle until it is enabled again. Disabled bundles are
+// persisted in the root directory.
+func (m *Manager) Disable(name string) (Info, error) {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	source, err := m.source(name)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if m.disabled[name] {
+		return m.info(name), nil
+	}
+
+	m.disabled[name] = true
+	if err := m.saveState(); err != nil {
+		delete(m.disabled, name)
+		return Info{}, err
+	}
+
+	m.lock.Lock()
+	current := m.bundles[source]
+	m.lock.Unlock()
+
+	if current != nil {
+		if runtime := current.bundle.Runtime(); runtime != nil {
+			runtime.Stop()
+		}
+	}
+
+	// disabled bundles do not affect the readiness
+	m.failures.Set(source, nil)
+
+	m.log.Info("automation bundle disabled", "name", name)
+
+	return m.info(name), nil
+}
+
+// Delete stops a bundle and removes it, including its parameter values,
+// from the root directory.
+func (m *Manager) Delete(name string) error {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	source, err := m.source(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(source); err != nil {
+		return fmt.Errorf("failed to delete bundle: %w", err)
+	}
+
+	if err := os.Remove(source + ".vars.json"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		m.log.Error("failed to delete bundle parameters", "name", name, "error", err)
+	}
+
+	if m.disabled[name] {
+		delete(m.disabled, name)
+
+		if err := m.saveState(); err != nil {
+			m.log.Error("failed to save bundle state", "error", err)
+		}
+	}
+
+	m.log.Info("automation bundle deleted", "name", name)
+
+	m.syncLocked(false)
+
+	return nil
+}
+
+// SetParameters replaces the vars.json of a bundle with values and reloads
+// the bundle. Only parameters declared in the bundle's package.json are
+// allowed.
+func (m *Manager) SetParameters(name string, values map[string]any) (Info, error) {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	source, err := m.source(name)
+	if err != nil {
+		return Info{}, err
+	}
+
+	m.lock.Lock()
+	current := m.bundles[source]
+	m.lock.Unlock()
+
+	if current == nil {
+		return Info{}, fmt.Errorf("%w: %s", ErrBundleNotLoaded, name)
+	}
+
+	for key := range values {
+		if _, ok := current.bundle.AutomationConfig.Parameters[key]; !ok {
+			return Info{}, fmt.Errorf("%w: %s", ErrUnknownParameter, key)
+		}
+	}
+
+	content, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		return Info{}, err
+	}
+
+	if err := writeFileAtomic(current.bundle.VarsPath(), content); err != nil {
+		return Info{}, fmt.Errorf("failed to write vars.json: %w", err)
+	}
+
+	m.log.Info("automation bundle parameters updated", "name", name)
+
+	// the changed vars.json changes the fingerprint of the source so the
+	// bundle is reloaded.
+	m.syncLocked(false)
+
+	return m.info(name), nil
+}
+
+func (m *Manager) scan(requireStable bool) {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	m.syncLocked(requireStable)
+}
+
+// syncLocked applies all changes of the root directory. m.scanLock must be
+// held.
+func (m *Manager) syncLocked(requireStable bool) {
+	entries, err := os.ReadDir(m.root)
+	if err != nil {
+		m.log.Error("failed to read automation bundles", "path", m.root, "error", err)
+		m.failures.Set(m.root, err)
+
+		return
+	}
+
+	m.failures.Set(m.root, nil)
+
+	seen := make(map[string]struct{}, len(entries))
+
+	for _, e := range entries {
+		if !isSource(e) {
+			continue
+		}
+
+		source := filepath.Join(m.root, e.Name())
+
+		fp, err := fingerprint(source, source+".vars.json")
+		if err != nil {
+			// the source might have been removed in the meantime
+			m.log.Warn("failed to inspect automation bundle", "path", source, "error", err)
+			continue
+		}
+
+		seen[source] = struct{}{}
+
+		m.lock.Lock()
+		current := m.bundles[source]
+		m.lock.Unlock()
+
+		if (current != nil && current.fingerprint == fp) || m.failed[source].fingerprint == fp {
+			delete(m.pending, source)
+			continue
+		}
+
+		if requireStable && m.pending[source] != fp {
+			m.pending[source] = fp
+			continue
+		}
+
+		delete(m.pending, source)
+
+		if m.disabled[e.Name()] {
+			m.loadDisabled(source, fp, current)
+		} else {
+			m.load(source, fp, current)
+		}
+	}
+
+	// stop all bundles that have been removed
+	m.lock.Lock()
+	var removed []*managedBundle
+	for source, mb := range m.bundles {
+		if _, ok := seen[source]; !ok {
+			removed = append(removed, mb)
+			delete(m.bundles, source)
+		}
+	}
+	m.lock.Unlock()
+
+	for _, mb := range removed {
+		m.log.Info("automation bundle removed, stopping", "name", mb.bundle.Name())
+		mb.bundle.Stop()
+	}
+
+	for source := range m.failed {
+		if _, ok := seen[source]; !ok {
+			delete(m.failed, source)
+			m.failures.Set(source, nil)
+		}
+	}
+
+	for source := range m.pending {
+		if _, ok := seen[source]; !ok {
+			delete(m.pending, source)
+		}
+	}
+
+	for source, logs := range m.logs {
+		if _, ok := seen[source]; !ok {
+			logs.Close()
+			delete(m.logs, source)
+		}
+	}
+}
+
+// load loads and prepares the bundle at source and replaces current, if
+// any. If the new version fails to load, current is kept running.
+func (m *Manager) load(source string, fp string, current *managedBundle) {
+	b, err := LoadSource(source)
+	if err != nil {
+		m.fail(source, fp, err, current != nil)
+		return
+	}
+
+	// hold back the subscriptions of the new version until the previous one
+	// has been stopped so events are never handled by both.
+	handover := &handoverBroker{Broker: m.broker}
+
+	b.logs = m.logBuffer(source)
+
+	if err := b.Prepare(m.cfg, handover, m.opts...); err != nil {
+		b.Stop()
+		m.fail(source, fp, err, current != nil)
+
+		return
+	}
+
+	if current != nil {
+		current.bundle.Stop()
+	}
+
+	handover.release()
+
+	m.replace(source, b, fp)
+	m.failures.Set(source, nil)
+
+	if current != nil {
+		m.log.Info("automation bundle reloaded", "name", source, "version", b.Version)
+	} else {
+		m.log.Info("automation bundle loaded", "name", source, "version", b.Version)
+	}
+}
+
+// loadDisabled loads the bundle at source without preparing it so it can be
+// inspected while it is disabled.
+func (m *Manager) loadDisabled(source string, fp string, current *managedBundle) {
+	b, err := LoadSource(source)
+	if err != nil {
+		m.failed[source] = failure{fingerprint: fp, err: err}
+		m.log.Error("failed to load disabled automation bundle", "name", source, "error", err)
+
+		return
+	}
+
+	if current != nil {
+		current.bundle.Stop()
+	}
+
+	m.replace(source, b, fp)
+}
+
+// logBuffer returns the log buffer for source. m.scanLock must be held.
+func (m *Manager) logBuffer(source string) *LogBuffer {
+	logs, ok := m.logs[source]
+	if !ok {
+		logs = NewLogBuffer(m.cfg.AutomationLogSize, m.cfg.AutomationLogMaxAge)
+		m.logs[source] = logs
+	}
+
+	return logs
+}
+
+func (m *Manager) replace(source string, b *Bundle, fp string) {
+	m.lock.Lock()
+	m.bundles[source] = &managedBundle{
+		bundle:      b,
+		fingerprint: fp,
+	}
+	m.lock.Unlock()
+
+	delete(m.failed, source)
+}
+
+func (m *Manager) fail(source string, fp string, err error, keepCurrent bool) {
+	m.failed[source] = failure{fingerprint: fp, err: err}
+
+	// the previous version keeps running so the bundle is still healthy,
+	// the error is reported by List and Get.
+	if !keepCurrent {
+		m.failures.Set(source, err)
+	}
+
+	if keepCurrent {
+		m.log.Error("failed to reload automation bundle, keeping previous version", "name", source, "error", err)
+	} else {
+		m.log.Error("failed to load automation bundle", "name", source, "error", err)
+	}
+}
+
+// source returns the path of the bundle source with the given name.
+// m.scanLock must be held.
+func (m *Manager) source(name string) (string, error) {
+	if !validName(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	source := filepath.Join(m.root, name)
+
+	m.lock.Lock()
+	_, loaded := m.bundles[source]
+	m.lock.Unlock()
+
+	if _, failed := m.failed[source]; !loaded && !failed {
+		return "", fmt.Errorf("%w: %s", ErrBundleNotFound, name)
+	}
+
+	return source, nil
+}
+
+// info returns information about the bundle with the given name.
+// m.scanLock must be held.
+func (m *Manager) info(name string) Info {
+	source := filepath.Join(m.root, name)
+
+	m.lock.Lock()
+	current := m.bundles[source]
+	m.lock.Unlock()
+
+	info := Info{
+		Name:   name,
+		Source: source,
+		State:  StateFailed,
+		Err:    m.failed[source].err,
+	}
+
+	if current != nil {
+		info.Bundle = current.bundle
+	}
+
+	switch {
+	case m.disabled[name]:
+		info.State = StateDisabled
+
+	case current != nil && current.bundle.Runtime() != nil && current.bundle.Runtime().Running():
+		info.State = StateRunning
+	}
+
+	return info
+}
+
+func (m *Manager) loadState() error {
+	content, err := os.ReadFile(filepath.Join(m.root, stateFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	var state managerState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", stateFile, err)
+	}
+
+	for _, name := range state.Disabled {
+		m.disabled[name] = true
+	}
+
+	return nil
+}
+
+// saveState persists the names of all disabled bundles. m.scanLock must be
+// held.
+func (m *Manager) saveState() error {
+	state := managerState{
+		Disabled: make([]string, 0, len(m.disabled)),
+	}
+
+	for name := range m.disabled {
+		state.Disabled = append(state.Disabled, name)
+	}
+
+	sort.Strings(state.Disabled)
+
+	content, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(m.root, stateFile), content); err != nil {
+		return fmt.Errorf("failed to save bundle state: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) stopAll() {
+	m.scanLock.Lock()
+	defer m.scanLock.Unlock()
+
+	m.lock.Lock()
+	bundles := m.bundles
+	m.bundles = make(map[string]*managedBundle)
+	m.lock.Unlock()
+
+	for _, mb := range bundles {
+		mb.bundle.Stop()
+	}
+}
+
+// validName reports whether name may be used as the name of a bundle in the
+// root directory.
+func validName(name string) bool {
+	return name != "" && name != ".." && filepath.Base(name) == name && name[0] != '.'
+}
+
+// writeFileAtomic writes content to a temporary file next to path and
+// renames it to path afterwards.
+func writeFileAtomic(path string, content []byte) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// fingerprint returns a value that changes whenever a file below source or
+// any of the optional extra paths is added, removed or modified.
+func fingerprint(source string, extra ...string) (string, error) {
+	h := fnv.New64a()
+
+	for idx, path := range append([]string{source}, extra...) {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(path, p)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(h, "%d\x00%s\x00%d\x00%d\x00%t\n", idx, rel, info.Size(), info.ModTime().UnixNano(), d.IsDir())
+
+			return nil
+		})
+
+		switch {
+		case err == nil:
+		case idx > 0 && errors.Is(err, fs.ErrNotExist):
+			// extra paths are optional
+		default:
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum64()), nil
+}
+
+// handoverBroker holds back the subscriptions of an engine until release is
+// called.
+type handoverBroker struct {
+	automation.Broker
+
+	lock     sync.Mutex
+	released bool
+	pending  []subscription
+}
+
+type subscription struct {
+	typeUrl string
+	msgs    chan *eventsv1.Event
+}
+
+func (h *handoverBroker) Subscribe(typeUrl string, msgs chan *eventsv1.Event) {
+	h.lock.Lock()
+	if !h.released {
+		h.pending = append(h.pending, subscription{typeUrl: typeUrl, msgs: msgs})
+		h.lock.Unlock()
+
+		return
+	}
+	h.lock.Unlock()
+
+	h.Broker.Subscribe(typeUrl, msgs)
+}
+
+func (h *handoverBroker) Unsubscribe(typeUrl string, msgs chan *eventsv1.Event) {
+	h.lock.Lock()
+	if !h.released {
+		for idx, sub := range h.pending {
+			if sub.typeUrl == typeUrl && sub.msgs == msgs {
+				h.pending = append(h.pending[:idx], h.pending[idx+1:]...)
+				break
+			}
+		}
+		h.lock.Unlock()
+
+		return
+	}
+	h.lock.Unlock()
+
+	h.Broker.Unsubscribe(typeUrl, msgs)
+}
+
+// release subscribes all pending subscriptions at the underlying broker and
+// passes any further calls through.
+func (h *handoverBroker) release() {
+	h.lock.Lock()
+	pending := h.pending
+	h.pending = nil
+	h.released = true
+	h.lock.Unlock()
+
+	for _, sub := range pending {
+		h.Broker.Subscribe(sub.typeUrl, sub.msgs)
+	}
+}
@@ -0,0 +1,271 @@
+package bundle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+)
+
+type fakeBroker struct {
+	lock          sync.Mutex
+	subscriptions map[chan *eventsv1.Event]string
+}
+
+func (f *fakeBroker) Publish(*eventsv1.Event) error { return nil }
+
+func (f *fakeBroker) Subscribe(typeUrl string, msgs chan *eventsv1.Event) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.subscriptions == nil {
+		f.subscriptions = make(map[chan *eventsv1.Event]string)
+	}
+
+	f.subscriptions[msgs] = typeUrl
+}
+
+func (f *fakeBroker) Unsubscribe(typeUrl string, msgs chan *eventsv1.Event) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.subscriptions, msgs)
+}
+
+func (f *fakeBroker) types() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var result []string
+	for _, typeUrl := range f.subscriptions {
+		result = append(result, typeUrl)
+	}
+
+	return result
+}
+
+func writeBundle(t *testing.T, dir string, script string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"version": "1.0.0"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.js"), []byte(script), 0o644))
+}
+
+func TestManagerReload(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "test")
+	b := &fakeBroker{}
+
+	m := NewManager(root, config.Config{}, b)
+
+	writeBundle(t, dir, `on("tkd.events.v1.A", () => {})`)
+	m.Sync()
+
+	require.Len(t, m.Bundles(), 1)
+	require.NotNil(t, m.Engine(dir))
+	require.Equal(t, []string{"tkd.events.v1.A"}, b.types())
+
+	first := m.Engine(dir)
+
+	// a changed script replaces the engine
+	writeBundle(t, dir, `on("tkd.events.v1.B", () => {})`)
+	m.Sync()
+
+	require.Len(t, m.Bundles(), 1)
+	require.NotSame(t, first, m.Engine(dir))
+	require.Equal(t, []string{"tkd.events.v1.B"}, b.types())
+	require.NoError(t, m.Check(context.Background()))
+
+	second := m.Engine(dir)
+
+	// a broken version keeps the previous one running
+	writeBundle(t, dir, `on("tkd.events.v1.C", () => {}); throw new Error("broken")`)
+	m.Sync()
+
+	require.Same(t, second, m.Engine(dir))
+	require.Equal(t, []string{"tkd.events.v1.B"}, b.types())
+	require.NoError(t, m.Check(context.Background()))
+
+	info, err := m.Get("test")
+	require.NoError(t, err)
+	require.Equal(t, StateRunning, info.State)
+	require.ErrorContains(t, info.Err, "broken")
+
+	// removing the bundle stops it
+	require.NoError(t, os.RemoveAll(dir))
+	m.Sync()
+
+	require.Empty(t, m.Bundles())
+	require.Empty(t, b.types())
+	require.NoError(t, m.Check(context.Background()))
+}
+
+func TestManagerWaitsForStableSources(t *testing.T) {
+	root := t.TempDir()
+	b := &fakeBroker{}
+
+	m := NewManager(root, config.Config{}, b)
+
+	writeBundle(t, filepath.Join(root, "test"), `on("tkd.events.v1.A", () => {})`)
+
+	// the first scan only records the change
+	m.scan(true)
+	require.Empty(t, m.Bundles())
+
+	m.scan(true)
+	require.Len(t, m.Bundles(), 1)
+
+	m.stopAll()
+	require.Empty(t, b.types())
+}
+
+func TestManagerRunWithoutReload(t *testing.T) {
+	root := t.TempDir()
+	b := &fakeBroker{}
+
+	m := NewManager(root, config.Config{}, b)
+
+	writeBundle(t, filepath.Join(root, "test"), `on("tkd.events.v1.A", () => {})`)
+	m.Sync()
+	require.Len(t, m.Bundles(), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Run(ctx, 0)
+	}()
+
+	// bundles are stopped on shutdown even if reloading is disabled
+	cancel()
+	<-done
+
+	require.Empty(t, m.Bundles())
+	require.Empty(t, b.types())
+}
+
+func TestManagerArchives(t *testing.T) {
+	root := t.TempDir()
+
+	content, err := os.ReadFile("./testdata/test.zip")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "test.zip"), content, 0o644))
+
+	m := NewManager(root, config.Config{}, &fakeBroker{})
+	m.Sync()
+
+	bundles := m.Bundles()
+	require.Len(t, bundles, 1)
+	require.Equal(t, filepath.Join(root, "test.zip"), bundles[0].Name())
+
+	unpacked := bundles[0].unpacked
+	require.DirExists(t, unpacked)
+
+	// the unpacked directory is removed with the bundle
+	require.NoError(t, os.Remove(filepath.Join(root, "test.zip")))
+	m.Sync()
+
+	require.NoDirExists(t, unpacked)
+}
+
+func TestManagerManagement(t *testing.T) {
+	root := t.TempDir()
+	b := &fakeBroker{}
+
+	content, err := os.ReadFile("./testdata/test.zip")
+	require.NoError(t, err)
+
+	m := NewManager(root, config.Config{}, b)
+
+	// invalid names and contents are rejected
+	_, err = m.Upload("../test.zip", content, false)
+	require.ErrorIs(t, err, ErrInvalidName)
+
+	_, err = m.Upload("test.zip", []byte("not an archive"), false)
+	require.ErrorIs(t, err, ErrInvalidBundle)
+	require.NoFileExists(t, filepath.Join(root, "test.zip"))
+
+	_, err = m.Upload("test.zip", make([]byte, MaxUploadSize+1), false)
+	require.ErrorIs(t, err, ErrInvalidBundle)
+	require.NoFileExists(t, filepath.Join(root, "test.zip"))
+
+	info, err := m.Upload("test.zip", content, false)
+	require.NoError(t, err)
+	require.Equal(t, StateRunning, info.State)
+	require.Len(t, m.List(), 1)
+
+	_, err = m.Upload("test.zip", content, false)
+	require.ErrorIs(t, err, ErrBundleExists)
+
+	_, err = m.Get("unknown")
+	require.ErrorIs(t, err, ErrBundleNotFound)
+
+	// disabling a bundle is persisted
+	info, err = m.Disable("test.zip")
+	require.NoError(t, err)
+	require.Equal(t, StateDisabled, info.State)
+	require.False(t, m.Engine(info.Source).Running())
+
+	m.stopAll()
+
+	m = NewManager(root, config.Config{}, b)
+	m.Sync()
+
+	info, err = m.Get("test.zip")
+	require.NoError(t, err)
+	require.Equal(t, StateDisabled, info.State)
+	require.Nil(t, m.Engine(info.Source))
+
+	info, err = m.Enable("test.zip")
+	require.NoError(t, err)
+	require.Equal(t, StateRunning, info.State)
+	require.True(t, m.Engine(info.Source).Running())
+
+	// deleting removes the source
+	require.NoError(t, m.Delete("test.zip"))
+	require.NoFileExists(t, filepath.Join(root, "test.zip"))
+	require.Empty(t, m.List())
+}
+
+func TestManagerSetParameters(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "test")
+
+	writeBundle(t, dir, `on("tkd.events.v1.A", () => {})`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+		"version": "1.0.0",
+		"automation": {
+			"parameters": {
+				"greeting": "hello"
+			}
+		}
+	}`), 0o644))
+
+	m := NewManager(root, config.Config{}, &fakeBroker{})
+	m.Sync()
+	defer m.stopAll()
+
+	first := m.Engine(dir)
+	require.NotNil(t, first)
+
+	_, err := m.SetParameters("test", map[string]any{"unknown": true})
+	require.ErrorIs(t, err, ErrUnknownParameter)
+
+	info, err := m.SetParameters("test", map[string]any{"greeting": "servus"})
+	require.NoError(t, err)
+	require.Equal(t, StateRunning, info.State)
+
+	values, err := info.Bundle.Values()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"greeting": "servus"}, values)
+
+	// the bundle has been reloaded with the new values
+	require.NotSame(t, first, m.Engine(dir))
+}
@@ -12,6 +12,10 @@ import (
 	"strings"
 )
 
+// MaxUnpackedSize is the maximum total size of all files extracted from a
+// bundle archive so compressed archives cannot fill up the disk.
+var MaxUnpackedSize int64 = 256 << 20
+
 // TODO(ppacher): for now we ignore any file and directory attributes like ownership and
 // permissions
 func unpackTar(isGzip bool, path string) (string, error) {
@@ -46,6 +50,9 @@ func unpackTar(isGzip bool, path string) (string, error) {
 		return "", err
 	}
 
+	// remaining holds the number of bytes that may still be extracted
+	remaining := MaxUnpackedSize
+
 	// unpack files and directories
 L:
 	for {
@@ -56,17 +63,29 @@ L:
 			break L
 		}
 
-		hd := filepath.Join(dest, header.Name)
+		var hd string
+
+		hd, err = entryPath(dest, header.Name)
+		if err != nil {
+			break L
+		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err = os.Mkdir(hd, 0755); err != nil {
+			if err = os.MkdirAll(hd, 0o755); err != nil {
 				err = fmt.Errorf("failed to create directory %q: %w", hd, err)
 				break L
 			}
 
 		case tar.TypeReg:
-			err = writeFile(tarReader, hd)
+			// like zip files, tar files do not need to contain entries for
+			// all parent directories.
+			if err = os.MkdirAll(filepath.Dir(hd), 0o755); err != nil {
+				err = fmt.Errorf("failed to create directory %q: %w", filepath.Dir(hd), err)
+				break L
+			}
+
+			err = writeFile(tarReader, hd, &remaining)
 			if err != nil {
 				break L
 			}
@@ -114,23 +133,32 @@ func unpackZip(path string) (string, error) {
 		return "", err
 	}
 
+	// remaining holds the number of bytes that may still be extracted
+	remaining := MaxUnpackedSize
+
 	// finally, actually unpack the files to the temporary directory.
 	for _, f := range r.File {
-		var err error
+		hd, err := entryPath(dest, f.Name)
 
-		if strings.HasSuffix(f.Name, "/") {
-			err = os.MkdirAll(filepath.Join(dest, f.Name), 0o755)
-		} else {
+		switch {
+		case err != nil:
+			// reported below
+
+		case strings.HasSuffix(f.Name, "/"):
+			err = os.MkdirAll(hd, 0o755)
+
+		default:
 			// ensure we create the parent directories as well as not all zip files
 			// contain entries for directories.
-			err = os.MkdirAll(filepath.Dir(filepath.Join(dest, f.Name)), 0o755)
+			err = os.MkdirAll(filepath.Dir(hd), 0o755)
 
 			// finnally, write the zip file to disk
 			if err == nil {
 				var fo io.ReadCloser
 				fo, err = f.Open()
 				if err == nil {
-					err = writeFile(fo, filepath.Join(dest, f.Name))
+					err = writeFile(fo, hd, &remaining)
+					fo.Close()
 				}
 			}
 		}
@@ -147,7 +175,19 @@ func unpackZip(path string) (string, error) {
 	return dest, nil
 }
 
-func writeFile(f io.Reader, dst string) error {
+// entryPath returns the path of the archive entry name within dest. Absolute
+// paths and paths that would escape dest are rejected.
+func entryPath(dest string, name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("illegal path %q in archive", name)
+	}
+
+	return filepath.Join(dest, name), nil
+}
+
+// writeFile writes the content of f to dst. At most remaining bytes are
+// written and remaining is reduced by the size of the file.
+func writeFile(f io.Reader, dst string, remaining *int64) error {
 	d, err := os.Create(dst)
 	if err != nil {
 		return fmt.Errorf("failed to create target file: %w", err)
@@ -155,9 +195,16 @@ func writeFile(f io.Reader, dst string) error {
 
 	defer d.Close()
 
-	if _, err := io.Copy(d, f); err != nil {
+	// read one more byte than allowed to detect files that exceed the limit
+	n, err := io.Copy(d, io.LimitReader(f, *remaining+1))
+	if err != nil {
 		return fmt.Errorf("failed to write zip file to dist: %w", err)
 	}
 
+	*remaining -= n
+	if *remaining < 0 {
+		return fmt.Errorf("%w: archive exceeds %d bytes when unpacked", ErrInvalidBundle, MaxUnpackedSize)
+	}
+
 	return nil
 }
@@ -1,8 +1,13 @@
 package bundle
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
 
@@ -109,3 +114,109 @@ func Test_unpackZip(t *testing.T) {
 
 	assert.Equal(t, expected, m)
 }
+
+func Test_unpackIllegalPaths(t *testing.T) {
+	for _, name := range []string{"../evil.js", "test/../../evil.js", "/tmp/evil.js"} {
+		dir := t.TempDir()
+
+		tarPath := filepath.Join(dir, "evil.tar")
+		f, err := os.Create(tarPath)
+		require.NoError(t, err)
+
+		tw := tar.NewWriter(f)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: 4}))
+		_, err = tw.Write([]byte("evil"))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, f.Close())
+
+		_, err = unpackTar(false, tarPath)
+		require.ErrorContains(t, err, "illegal path", name)
+
+		zipPath := filepath.Join(dir, "evil.zip")
+		f, err = os.Create(zipPath)
+		require.NoError(t, err)
+
+		zw := zip.NewWriter(f)
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("evil"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		require.NoError(t, f.Close())
+
+		_, err = unpackZip(zipPath)
+		require.ErrorContains(t, err, "illegal path", name)
+	}
+}
+
+func Test_unpackLimits(t *testing.T) {
+	defer func(size int64) {
+		MaxUnpackedSize = size
+	}(MaxUnpackedSize)
+
+	MaxUnpackedSize = 1024
+
+	dir := t.TempDir()
+
+	// a small compressed archive that expands beyond the limit
+	content := bytes.Repeat([]byte("a"), 600)
+
+	tarPath := filepath.Join(dir, "bomb.tar.gz")
+	f, err := os.Create(tarPath)
+	require.NoError(t, err)
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, name := range []string{"a.js", "b.js"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}))
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = unpackTar(true, tarPath)
+	require.ErrorIs(t, err, ErrInvalidBundle)
+
+	zipPath := filepath.Join(dir, "bomb.zip")
+	f, err = os.Create(zipPath)
+	require.NoError(t, err)
+
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"a.js", "b.js"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = unpackZip(zipPath)
+	require.ErrorIs(t, err, ErrInvalidBundle)
+}
+
+func Test_unpackTarWithoutDirectories(t *testing.T) {
+	tarPath := filepath.Join(t.TempDir(), "nested.tar")
+	f, err := os.Create(tarPath)
+	require.NoError(t, err)
+
+	// the archive does not contain entries for the parent directories
+	tw := tar.NewWriter(f)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "test/lib/lib.js", Typeflag: tar.TypeReg, Mode: 0o644, Size: 3}))
+	_, err = tw.Write([]byte("lib"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, f.Close())
+
+	base, err := unpackTar(false, tarPath)
+	require.NoError(t, err)
+
+	defer os.RemoveAll(base)
+
+	content, err := os.ReadFile(filepath.Join(base, "test", "lib", "lib.js"))
+	require.NoError(t, err)
+	require.Equal(t, "lib", string(content))
+}
@@ -2,9 +2,17 @@ package automation
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	connect_go "github.com/bufbuild/connect-go"
@@ -14,8 +22,13 @@ import (
 	longrunningv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/longrunning/v1"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/longrunning/v1/longrunningv1connect"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/wellknown"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules/connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/metrics"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
 	"github.com/tierklinik-dobersberg/longrunning-service/pkg/op"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -24,6 +37,7 @@ import (
 type Broker interface {
 	Publish(*eventsv1.Event) error
 	Subscribe(string, chan *eventsv1.Event)
+	Unsubscribe(string, chan *eventsv1.Event)
 }
 
 type CoreModule struct {
@@ -31,6 +45,55 @@ type CoreModule struct {
 	broker Broker
 
 	scheduler *cron.Cron
+
+	// lock protects all fields below
+	lock         sync.Mutex
+	running      bool
+	handlers     []*eventHandler
+	schedules    map[int]*scheduleEntry
+	nextSchedule int
+
+	// wg tracks the subscription loops of all event handlers
+	wg sync.WaitGroup
+
+	// stopped is closed once the module is stopped.
+	stopped chan struct{}
+
+	// timers holds the handles of all active timers and intervals. It is
+	// only accessed on the event loop.
+	timers map[any]struct{}
+
+	// watch is the watchdog that is active for the code executing on the
+	// event loop. It is only accessed on the event loop.
+	watch *watch
+}
+
+// watch is a running watchdog.
+type watch struct {
+	// interrupted is set once the watchdog interrupted the runtime.
+	interrupted atomic.Bool
+
+	stop func()
+}
+
+// eventHandler is a handler registered using on().
+type eventHandler struct {
+	id       string
+	event    string
+	callable goja.Callable
+
+	// msgs is the channel subscribed at the broker while the engine is
+	// running.
+	msgs chan *eventsv1.Event
+}
+
+// scheduleEntry is a callback registered using schedule().
+type scheduleEntry struct {
+	schedule string
+	callable goja.Callable
+
+	// entry is the ID of the cron entry while the engine is running.
+	entry cron.EntryID
 }
 
 func NewCoreModule(engine *Engine, broker Broker) *CoreModule {
@@ -48,10 +111,10 @@ func NewCoreModule(engine *Engine, broker Broker) *CoreModule {
 		engine:    engine,
 		scheduler: scheduler,
 		broker:    broker,
+		schedules: make(map[int]*scheduleEntry),
+		timers:    make(map[any]struct{}),
 	}
 
-	cm.scheduler.Start()
-
 	return cm
 }
 
@@ -60,29 +123,80 @@ func (c *CoreModule) Enable(r *goja.Runtime) {
 	r.Set("clearSchedule", c.clearSchedule)
 	r.Set("on", c.onEvent)
 	r.Set("publish", c.publish)
+
+	c.enableTimers(r)
 }
 
 func (c *CoreModule) schedule(schedule string, callable goja.Callable) (int, error) {
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return -1, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if limit := c.engine.Limits().Schedules; limit > 0 && len(c.schedules) >= limit {
+		return -1, fmt.Errorf("%w: at most %d schedules", modules.ErrLimitExceeded, limit)
+	}
+
+	c.nextSchedule++
+	id := c.nextSchedule
+
+	entry := &scheduleEntry{
+		schedule: schedule,
+		callable: callable,
+	}
+	c.schedules[id] = entry
+
+	if c.running {
+		if err := c.addSchedule(entry); err != nil {
+			delete(c.schedules, id)
+			return -1, err
+		}
+	}
+
 	c.engine.log.Info("automation: new schedule registered", "schedule", schedule)
 
-	res, err := c.scheduler.AddFunc(schedule, func() {
-		c.engine.log.Info("triggering automation schedule", "schedule", schedule)
+	return id, nil
+}
 
-		c.wrapOperation(callable, "schedule:"+fmt.Sprintf("%q", schedule), nil)
+// addSchedule adds entry to the scheduler. c.lock must be held.
+func (c *CoreModule) addSchedule(entry *scheduleEntry) error {
+	res, err := c.scheduler.AddFunc(entry.schedule, func() {
+		c.runSchedule(entry.schedule, entry.callable)
 	})
 	if err != nil {
-		return -1, err
+		return err
 	}
 
-	return int(res), nil
+	entry.entry = res
+
+	return nil
 }
 
-func (c *CoreModule) wrapOperation(callable goja.Callable, kind string, this any, args ...any) {
+func (c *CoreModule) runSchedule(schedule string, callable goja.Callable) {
+	// When running multiple replicas, only the current leader is allowed
+	// to execute schedules.
+	if !c.engine.elector.IsLeader() {
+		c.engine.log.Debug("skipping automation schedule, not the current leader", "schedule", schedule)
+		return
+	}
+
+	c.engine.log.Info("triggering automation schedule", "schedule", schedule)
+
+	c.wrapOperation(context.Background(), callable, "schedule:"+fmt.Sprintf("%q", schedule), nil, nil)
+}
+
+// wrapOperation executes callable on the event loop, optionally wrapped in a
+// long-running operation. Promises returned by async handlers are awaited.
+// If callable throws or its promise is rejected, onError is called on the
+// event loop. onError may be nil. The execution is traced as a child of ctx.
+func (c *CoreModule) wrapOperation(ctx context.Context, callable goja.Callable, kind string, onError func(error), this any, args ...any) {
 	var cli longrunningv1connect.LongRunningServiceClient
 	if c.engine.automationConfig.WrapInOperation {
 		var err error
 
-		cli, err = wellknown.LongRunningService.Create(context.Background(), c.engine.discoverer)
+		cli, err = wellknown.LongRunningService.Create(ctx, c.engine.discoverer)
 		if err != nil {
 			c.engine.log.Error("failed to get longrunning service instance", "error", err)
 		}
@@ -90,41 +204,21 @@ func (c *CoreModule) wrapOperation(callable goja.Callable, kind string, this any
 
 	if cli == nil {
 		c.engine.loop.RunOnLoop(func(r *goja.Runtime) {
-			this := r.ToValue(this)
-			a := make([]goja.Value, len(args))
-			for idx, arg := range args {
-				a[idx] = r.ToValue(arg)
-			}
+			c.invoke(ctx, r, callable, kind, this, args, func(_ goja.Value, err error) {
+				if err != nil {
+					c.engine.log.Error("failed to execute goja callable", "error", err)
 
-			callable(this, a...)
+					if onError != nil {
+						onError(err)
+					}
+				}
+			})
 		})
 	} else {
-		_, err := op.Wrap(context.Background(), cli, func(context.Context) (any, error) {
-
-			var (
-				result    any
-				resultErr error
-			)
-
+		_, err := op.Wrap(ctx, cli, func(context.Context) (any, error) {
 			c.engine.log.Info("scheduling operation on event loop")
 
-			c.engine.loop.RunOnLoop(func(r *goja.Runtime) {
-				this := r.ToValue(this)
-				a := make([]goja.Value, len(args))
-				for idx, arg := range args {
-					a[idx] = r.ToValue(arg)
-				}
-
-				gv, err := callable(this, a...)
-
-				if err == nil {
-					result = gv.Export()
-				} else {
-					resultErr = err
-				}
-			})
-
-			return result, resultErr
+			return c.execute(ctx, callable, kind, onError, this, args...)
 		}, func(req *connect_go.Request[longrunningv1.RegisterOperationRequest]) {
 			req.Msg.Kind = kind
 			req.Msg.Owner = "automation"
@@ -144,8 +238,228 @@ func (c *CoreModule) wrapOperation(callable goja.Callable, kind string, this any
 	}
 }
 
+// execute executes callable on the event loop and waits for its result. For
+// async handlers, it waits until the returned promise is settled. If
+// callable fails, onError is called on the event loop before execute
+// returns. onError may be nil. execute must not be called on the event loop.
+func (c *CoreModule) execute(ctx context.Context, callable goja.Callable, kind string, onError func(error), this any, args ...any) (any, error) {
+	type result struct {
+		value any
+		err   error
+	}
+
+	stopped := c.stoppedChan()
+	results := make(chan result, 1)
+
+	if !c.engine.loop.RunOnLoop(func(r *goja.Runtime) {
+		c.invoke(ctx, r, callable, kind, this, args, func(value goja.Value, err error) {
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+
+				results <- result{err: err}
+				return
+			}
+
+			results <- result{value: value.Export()}
+		})
+	}) {
+		return nil, ErrEngineStopped
+	}
+
+	// pending promises are never settled once the engine has been stopped
+	select {
+	case res := <-results:
+		return res.value, res.err
+	case <-stopped:
+		return nil, ErrEngineStopped
+	}
+}
+
+// invoke converts this and args to JavaScript values and calls callable. It
+// must be called on the event loop.
+func (c *CoreModule) invoke(ctx context.Context, r *goja.Runtime, callable goja.Callable, kind string, this any, args []any, done func(goja.Value, error)) {
+	a := make([]goja.Value, len(args))
+	for idx, arg := range args {
+		a[idx] = r.ToValue(arg)
+	}
+
+	c.call(ctx, callable, kind, r.ToValue(this), done, a...)
+}
+
+// call executes callable and records execution metrics and a span as a
+// child of ctx. kind describes the trigger of the execution, i.e.
+// `event:"<type-url>"`. While callable, its timers or the continuations of
+// its promises are executing, the span's context is available using
+// Engine.Context. If callable returns a promise, it is awaited. done is called with the result once callable returned or its
+// promise is settled. call and done are invoked on the event loop.
+func (c *CoreModule) call(ctx context.Context, callable goja.Callable, kind string, this goja.Value, done func(goja.Value, error), args ...goja.Value) {
+	// jobs that were queued before the engine has been stopped are still
+	// executed when the event loop is terminated.
+	if !c.isRunning() {
+		done(nil, ErrEngineStopped)
+		return
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "automation "+kind, trace.WithAttributes(
+		attribute.String("automation.bundle", c.engine.name),
+		attribute.String("automation.trigger", kind),
+	))
+
+	trigger := kind
+	kind, _, _ = strings.Cut(kind, ":")
+
+	start := time.Now()
+
+	finish := func(value goja.Value, err error) {
+		duration := time.Since(start)
+
+		tracing.End(span, err)
+
+		metrics.AutomationExecutions.WithLabelValues(c.engine.name, kind).Inc()
+		metrics.AutomationDuration.WithLabelValues(c.engine.name, kind).Observe(duration.Seconds())
+
+		if err != nil {
+			metrics.AutomationErrors.WithLabelValues(c.engine.name, kind).Inc()
+		}
+
+		switch {
+		case errors.Is(err, ErrHandlerTimeout):
+			metrics.AutomationTimeouts.WithLabelValues(c.engine.name, kind).Inc()
+
+			c.engine.console.Error(fmt.Sprintf("%s interrupted after %s: %s", trigger, duration.Round(time.Millisecond), err))
+
+		case duration > c.engine.HandlerWarnThreshold() && c.engine.HandlerWarnThreshold() > 0:
+			c.engine.console.Warn(fmt.Sprintf("%s took %s, which exceeds the warning threshold of %s", trigger, duration.Round(time.Millisecond), c.engine.HandlerWarnThreshold()))
+		}
+
+		done(value, err)
+	}
+
+	restore := c.engine.enter(ctx)
+	stop := c.guard()
+
+	value, err := callable(this, args...)
+
+	stop()
+	restore()
+
+	if err != nil {
+		finish(nil, err)
+		return
+	}
+
+	var deadline time.Time
+	if timeout := c.engine.HandlerTimeout(); timeout > 0 {
+		deadline = start.Add(timeout)
+	}
+
+	c.await(c.engine.rt, value, deadline, finish)
+}
+
+// guard starts the watchdog for code that is executed on the event loop. If a
+// watchdog is already active, i.e. for promise reactions that are executed
+// as part of a handler, the code is covered by that one instead. The returned
+// function must be called as soon as the code returns. It must be called on
+// the event loop.
+func (c *CoreModule) guard() func() {
+	if c.watch != nil {
+		if !c.watch.interrupted.Load() {
+			return func() {}
+		}
+
+		// the code the watchdog belongs to has been interrupted but did not
+		// return, i.e. a promise reaction that aborted the job queue.
+		c.release(c.watch)
+	}
+
+	w := c.watchdog()
+	c.watch = w
+
+	return func() {
+		c.release(w)
+	}
+}
+
+// release stops w unless it has already been replaced. It must be called on
+// the event loop.
+func (c *CoreModule) release(w *watch) {
+	if c.watch == w {
+		w.stop()
+		c.watch = nil
+	}
+}
+
+// watchdog interrupts the runtime once the handler timeout is exceeded. The
+// stop function of the returned watch must be called as soon as the handler
+// returns and resets the interrupt so the runtime stays usable. It must be
+// called on the event loop.
+func (c *CoreModule) watchdog() *watch {
+	w := &watch{
+		stop: func() {},
+	}
+
+	timeout := c.engine.HandlerTimeout()
+	if timeout <= 0 {
+		return w
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			c.interrupt(w, ErrHandlerTimeout)
+		}
+	}()
+
+	w.stop = func() {
+		close(done)
+		<-finished
+
+		// the watchdog might have interrupted the runtime after the handler
+		// returned
+		c.engine.rt.ClearInterrupt()
+	}
+
+	return w
+}
+
+// interrupt interrupts the runtime with err on behalf of w.
+func (c *CoreModule) interrupt(w *watch, err error) {
+	w.interrupted.Store(true)
+	c.engine.rt.Interrupt(err)
+
+	// promise reactions that are interrupted abort the job queue and never
+	// return to the code that started w, so w is released on the loop
+	// afterwards.
+	c.engine.loop.RunOnLoop(func(*goja.Runtime) {
+		c.release(w)
+	})
+}
+
 func (c *CoreModule) clearSchedule(id int) {
-	c.scheduler.Remove(cron.EntryID(id))
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.schedules[id]
+	if !ok {
+		return
+	}
+
+	delete(c.schedules, id)
+
+	if entry.entry != 0 {
+		c.scheduler.Remove(entry.entry)
+	}
 }
 
 func (c *CoreModule) publish(typeUrl string, obj *goja.Object) error {
@@ -169,32 +483,232 @@ func (c *CoreModule) publish(typeUrl string, obj *goja.Object) error {
 		return err
 	}
 
-	return c.broker.Publish(&eventsv1.Event{
+	pb := &eventsv1.Event{
 		Event: evt,
-	})
+	}
+
+	_, span := tracing.StartPublish(c.engine.Context(), pb)
+
+	err = c.broker.Publish(pb)
+	tracing.End(span, err)
+
+	return err
+}
+
+func (c *CoreModule) onEvent(event string, fn goja.Value) error {
+	callable, ok := goja.AssertFunction(fn)
+	if !ok {
+		return fmt.Errorf("event handler must be a function")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if limit := c.engine.Limits().Subscriptions; limit > 0 && len(c.handlers) >= limit {
+		return fmt.Errorf("%w: at most %d event handlers", modules.ErrLimitExceeded, limit)
+	}
+
+	h := &eventHandler{
+		id:       c.handlerID(event, fn.String()),
+		event:    event,
+		callable: callable,
+	}
+
+	idx := len(c.handlers)
+	c.handlers = append(c.handlers, h)
+
+	if c.running {
+		c.subscribe(idx, h)
+	}
+
+	return nil
+}
+
+// handlerID returns a stable identifier for an event handler based on the
+// subscription and the source code of the handler so dead letters are not
+// re-driven to a different handler once the bundle has been changed.
+// Identical handlers are numbered in the order they are registered. c.lock
+// must be held.
+func (c *CoreModule) handlerID(event string, source string) string {
+	sum := sha256.Sum256([]byte(event + "\x00" + source))
+	id := hex.EncodeToString(sum[:8])
+
+	count := 0
+	for _, h := range c.handlers {
+		if strings.HasPrefix(h.id, id) {
+			count++
+		}
+	}
+
+	if count > 0 {
+		id += "-" + strconv.Itoa(count)
+	}
+
+	return id
 }
 
-func (c *CoreModule) onEvent(event string, callable goja.Callable) {
+// subscribe subscribes the event handler h at the broker and starts the
+// subscription loop. c.lock must be held.
+func (c *CoreModule) subscribe(idx int, h *eventHandler) {
 	msgs := make(chan *eventsv1.Event, 100)
+	h.msgs = msgs
 
-	c.broker.Subscribe(event, msgs)
+	c.broker.Subscribe(h.event, msgs)
 
-	c.engine.log.Info("automation: script successfully subscribed to event topic", "event", event)
+	c.engine.log.Info("automation: script successfully subscribed to event topic", "event", h.event)
 
+	c.wg.Add(1)
 	go func() {
-		defer c.engine.log.Info("automation: subscription loop closed")
+		defer c.wg.Done()
+		defer c.engine.log.Info("automation: subscription loop closed", "event", h.event)
+
 		for m := range msgs {
+			if !c.isRunning() {
+				continue
+			}
+
 			c.engine.log.Info("automation: received event, converting from proto-message", "typeUrl", m.Event.TypeUrl)
 
 			o, err := connect.ConvertProtoMessage(m, c.engine.resolver)
 			if err != nil {
 				c.engine.log.Error("failed to convert protobuf message", "error", err)
+				c.deadLetter(h, idx, m, err)
 				continue
 			}
 
 			c.engine.log.Info("running automation for event", "typeUrl", m.Event.TypeUrl)
 
-			c.wrapOperation(callable, "event:"+fmt.Sprintf("%q", event), nil, o)
+			c.wrapOperation(tracing.Extract(context.Background(), m), h.callable, "event:"+fmt.Sprintf("%q", h.event), func(err error) {
+				c.deadLetter(h, idx, m, err)
+			}, nil, o)
 		}
 	}()
 }
+
+// redeliver synchronously executes the event handler identified by
+// handlerID for m.
+func (c *CoreModule) redeliver(handlerID string, m *eventsv1.Event) error {
+	c.lock.Lock()
+	idx := slices.IndexFunc(c.handlers, func(h *eventHandler) bool {
+		return handlerID != "" && h.id == handlerID
+	})
+	if idx < 0 {
+		c.lock.Unlock()
+		return fmt.Errorf("%w: %q", ErrNoHandler, handlerID)
+	}
+	h := c.handlers[idx]
+	c.lock.Unlock()
+
+	o, err := connect.ConvertProtoMessage(m, c.engine.resolver)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.execute(tracing.Extract(context.Background(), m), h.callable, "redrive", nil, nil, o)
+
+	return err
+}
+
+// stoppedChan returns a channel that is closed once the module is stopped.
+func (c *CoreModule) stoppedChan() <-chan struct{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.running {
+		stopped := make(chan struct{})
+		close(stopped)
+
+		return stopped
+	}
+
+	return c.stopped
+}
+
+func (c *CoreModule) isRunning() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.running
+}
+
+// start subscribes all event handlers at the broker and starts the
+// scheduler with all registered schedules.
+func (c *CoreModule) start() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.running {
+		return
+	}
+	c.running = true
+	c.stopped = make(chan struct{})
+
+	for idx, h := range c.handlers {
+		c.subscribe(idx, h)
+	}
+
+	for _, entry := range c.schedules {
+		if err := c.addSchedule(entry); err != nil {
+			// the schedule has already been validated by schedule()
+			c.engine.log.Error("failed to add schedule", "schedule", entry.schedule, "error", err)
+		}
+	}
+
+	c.scheduler.Start()
+}
+
+// stop stops the scheduler, unsubscribes all event handlers from the broker
+// and waits for their subscription loops to finish. Events that are already
+// queued are not handled anymore. The registered handlers and schedules are
+// kept so the module can be started again.
+func (c *CoreModule) stop() {
+	c.lock.Lock()
+
+	if !c.running {
+		c.lock.Unlock()
+		return
+	}
+	c.running = false
+	close(c.stopped)
+
+	done := c.scheduler.Stop()
+
+	for _, entry := range c.schedules {
+		c.scheduler.Remove(entry.entry)
+		entry.entry = 0
+	}
+
+	for _, h := range c.handlers {
+		c.broker.Unsubscribe(h.event, h.msgs)
+
+		// the broker does not send to msgs anymore, closing it terminates
+		// the subscription loop
+		close(h.msgs)
+		h.msgs = nil
+	}
+
+	c.lock.Unlock()
+
+	<-done.Done()
+	c.wg.Wait()
+}
+
+func (c *CoreModule) deadLetter(h *eventHandler, idx int, m *eventsv1.Event, err error) {
+	if c.engine.deadLetters == nil {
+		return
+	}
+
+	msg, stack := ErrorDetails(err)
+
+	if err := c.engine.deadLetters.Put(DeadLetter{
+		Bundle:       c.engine.name,
+		Subscription: h.event,
+		Handler:      idx,
+		HandlerID:    h.id,
+		Event:        m,
+		Error:        msg,
+		Stack:        stack,
+	}); err != nil {
+		c.engine.log.Error("failed to store dead letter", "event", h.event, "error", err)
+	}
+}
@@ -1,14 +1,37 @@
 package automation
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dop251/goja"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
 	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/metrics"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
+// staticElector is a leader.Elector with a manually controlled leadership
+// state.
+type staticElector struct {
+	leader atomic.Bool
+}
+
+func (s *staticElector) Set(isLeader bool) { s.leader.Store(isLeader) }
+func (s *staticElector) IsLeader() bool    { return s.leader.Load() }
+
 type mockBroker struct {
 	events        []*eventsv1.Event
 	subscriptions map[string]chan *eventsv1.Event
@@ -27,6 +50,12 @@ func (m *mockBroker) Subscribe(topic string, msgs chan *eventsv1.Event) {
 	m.subscriptions[topic] = msgs
 }
 
+func (m *mockBroker) Unsubscribe(topic string, msgs chan *eventsv1.Event) {
+	if m.subscriptions[topic] == msgs {
+		delete(m.subscriptions, topic)
+	}
+}
+
 func Test_CoreModule(t *testing.T) {
 	done := make(chan struct{})
 
@@ -47,9 +76,9 @@ func Test_CoreModule(t *testing.T) {
 	engine.Run(func(r *goja.Runtime) (goja.Value, error) {
 		_, err := r.RunString(`
 		var i = 0;
-		var id = scheulde("* * * * *", () => {
+		var id = schedule("* * * * *", () => {
 			i++;
-			error("running ...")
+			console.log("running ...")
 
 			if (i === 2) {
 				clearSchedule(id)
@@ -60,7 +89,21 @@ func Test_CoreModule(t *testing.T) {
 		return nil, err
 	})
 
-	<-done
+	// run the registered cron job directly instead of waiting for two
+	// minutes to pass
+	entries := engine.core.scheduler.Entries()
+	require.Len(t, entries, 1)
+
+	entries[0].Job.Run()
+	entries[0].Job.Run()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("schedule has not been executed twice")
+	}
+
+	require.Empty(t, engine.core.scheduler.Entries())
 }
 
 func TestPubSub(t *testing.T) {
@@ -85,3 +128,662 @@ func TestPubSub(t *testing.T) {
 	require.NotEmpty(t, b.events)
 	require.Equal(t, b.events[0].Event.TypeUrl, "type.googleapis.com/tkd.tasks.v1.TaskEvent")
 }
+
+func TestScheduleLeaderElection(t *testing.T) {
+	elector := &staticElector{}
+
+	rt, err := New("test", config.Config{}, nil, WithLeaderElector(elector))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	var calls = 0;
+	function callback() {
+		calls++;
+	}
+	`)
+	require.NoError(t, err)
+
+	value, err := rt.Run(func(r *goja.Runtime) (goja.Value, error) {
+		return r.Get("callback"), nil
+	})
+	require.NoError(t, err)
+
+	callback, ok := goja.AssertFunction(value)
+	require.True(t, ok)
+
+	getCalls := func() int64 {
+		value, err := rt.RunScript("calls")
+		require.NoError(t, err)
+
+		return value.ToInteger()
+	}
+
+	// not the leader, the schedule must not be executed
+	rt.core.runSchedule("* * * * *", callback)
+	require.Equal(t, int64(0), getCalls())
+
+	// once we are the leader, schedules are executed again
+	elector.Set(true)
+	rt.core.runSchedule("* * * * *", callback)
+	require.Equal(t, int64(1), getCalls())
+}
+
+type memoryDeadLetters struct {
+	letters chan DeadLetter
+}
+
+func (m *memoryDeadLetters) Put(letter DeadLetter) error {
+	m.letters <- letter
+	return nil
+}
+
+func TestDeadLetterAndRedeliver(t *testing.T) {
+	b := &mockBroker{}
+	dlq := &memoryDeadLetters{letters: make(chan DeadLetter, 1)}
+
+	rt, err := New("test", config.Config{}, b, WithDeadLetterQueue(dlq))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	var fail = true;
+	var handled = 0;
+
+	on("tkd.events.v1.Event", () => {})
+	on("tkd.events.v1.Event", () => {
+		if (fail) {
+			throw new Error("boom")
+		}
+
+		handled++;
+	})
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	// the mock broker only keeps the latest subscription so this is only
+	// delivered to the second, failing handler.
+	evt := &eventsv1.Event{Event: payload}
+	b.subscriptions["tkd.events.v1.Event"] <- evt
+
+	letter := <-dlq.letters
+	require.Equal(t, "test", letter.Bundle)
+	require.Equal(t, "tkd.events.v1.Event", letter.Subscription)
+	require.Equal(t, 1, letter.Handler)
+	require.Contains(t, letter.Error, "boom")
+	require.NotEmpty(t, letter.Stack)
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.AutomationErrors.WithLabelValues("test", "event")))
+
+	// re-driving still fails and is reported to the caller
+	err = rt.Redeliver(letter.HandlerID, letter.Event)
+	require.ErrorContains(t, err, "boom")
+
+	// once fixed, re-driving succeeds
+	_, err = rt.RunScript("fail = false")
+	require.NoError(t, err)
+
+	require.NoError(t, rt.Redeliver(letter.HandlerID, letter.Event))
+
+	value, err := rt.RunScript("handled")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), value.ToInteger())
+
+	err = rt.Redeliver("unknown", letter.Event)
+	require.ErrorIs(t, err, ErrNoHandler)
+}
+
+func TestRedeliverAfterReload(t *testing.T) {
+	b := &mockBroker{}
+	dlq := &memoryDeadLetters{letters: make(chan DeadLetter, 1)}
+
+	rt, err := New("test", config.Config{}, b, WithDeadLetterQueue(dlq))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	on("tkd.events.v1.Event", (evt) => { throw new Error("boom") })
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	b.subscriptions["tkd.events.v1.Event"] <- &eventsv1.Event{Event: payload}
+
+	letter := <-dlq.letters
+	require.NotEmpty(t, letter.HandlerID)
+
+	// the reloaded bundle registers another handler first, the failed
+	// handler is still found by its ID.
+	reloaded, err := New("test", config.Config{}, &mockBroker{})
+	require.NoError(t, err)
+
+	_, err = reloaded.RunScript(`
+	var other = 0;
+
+	on("tkd.events.v1.Event", () => { other++ })
+	on("tkd.events.v1.Event", (evt) => { throw new Error("boom") })
+	`)
+	require.NoError(t, err)
+
+	require.ErrorContains(t, reloaded.Redeliver(letter.HandlerID, letter.Event), "boom")
+
+	value, err := reloaded.RunScript("other")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), value.ToInteger())
+
+	// once the handler has been changed, the dead letter cannot be re-driven
+	// anymore.
+	changed, err := New("test", config.Config{}, &mockBroker{})
+	require.NoError(t, err)
+
+	_, err = changed.RunScript(`
+	on("tkd.events.v1.Event", () => {})
+	`)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, changed.Redeliver(letter.HandlerID, letter.Event), ErrNoHandler)
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	b := &mockBroker{}
+	done := make(chan struct{})
+
+	rt, err := New("test", config.Config{}, b, func(e *Engine) {
+		e.Run(func(r *goja.Runtime) (goja.Value, error) {
+			r.Set("done", func() {
+				close(done)
+			})
+			return nil, nil
+		})
+	})
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	on("tkd.events.v1.Event", () => {
+		publish("tkd.tasks.v1.TaskEvent", {})
+		done()
+	})
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "parent")
+	parent.End()
+
+	evt := &eventsv1.Event{Event: payload}
+	tracing.Inject(ctx, evt)
+
+	b.subscriptions["tkd.events.v1.Event"] <- evt
+	<-done
+
+	var handler sdktrace.ReadOnlySpan
+	require.Eventually(t, func() bool {
+		for _, span := range recorder.Ended() {
+			if span.Name() == `automation event:"tkd.events.v1.Event"` {
+				handler = span
+				return true
+			}
+		}
+
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// the handler continues the trace of the event
+	require.Equal(t, parent.SpanContext().TraceID(), handler.SpanContext().TraceID())
+	require.Equal(t, parent.SpanContext().SpanID(), handler.Parent().SpanID())
+
+	// events published by the handler are part of the same trace
+	require.Len(t, b.events, 1)
+	sc := trace.SpanContextFromContext(tracing.Extract(context.Background(), b.events[0]))
+	require.Equal(t, parent.SpanContext().TraceID(), sc.TraceID())
+}
+
+func TestTracingAsync(t *testing.T) {
+	b := &mockBroker{}
+	done := make(chan struct{})
+
+	rt, err := New("test", config.Config{}, b, func(e *Engine) {
+		e.Run(func(r *goja.Runtime) (goja.Value, error) {
+			r.Set("done", func() {
+				close(done)
+			})
+
+			// later returns a promise that is resolved outside of the
+			// handler, like the ones of outbound calls
+			r.Set("later", func() *goja.Promise {
+				promise, resolve, _ := r.NewPromise()
+
+				go e.EventLoop().RunOnLoop(func(*goja.Runtime) {
+					resolve(nil)
+				})
+
+				return promise
+			})
+			return nil, nil
+		})
+	})
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	on("tkd.events.v1.Event", async () => {
+		await later()
+		publish("tkd.tasks.v1.TaskEvent", {})
+
+		setTimeout(() => {
+			publish("tkd.tasks.v1.TaskEvent", {})
+			done()
+		}, 10)
+	})
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	traceID := trace.TraceID{1}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	evt := &eventsv1.Event{Event: payload}
+	tracing.Inject(ctx, evt)
+
+	b.subscriptions["tkd.events.v1.Event"] <- evt
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not finish")
+	}
+
+	// code after await and timer callbacks continue the trace of the handler
+	require.Len(t, b.events, 2)
+	for _, published := range b.events {
+		sc := trace.SpanContextFromContext(tracing.Extract(context.Background(), published))
+		require.Equal(t, traceID, sc.TraceID())
+	}
+}
+
+func TestEngineLifecycle(t *testing.T) {
+	b := &mockBroker{}
+	handled := make(chan struct{}, 1)
+
+	rt, err := New("test", config.Config{}, b, func(e *Engine) {
+		e.Run(func(r *goja.Runtime) (goja.Value, error) {
+			r.Set("handled", func() {
+				handled <- struct{}{}
+			})
+			return nil, nil
+		})
+	})
+	require.NoError(t, err)
+	require.True(t, rt.Running())
+
+	_, err = rt.RunScript(`
+	var fired = false;
+
+	on("tkd.events.v1.Event", () => handled())
+	schedule("* * * * *", () => {})
+	schedule("0 * * * *", () => {}) // removed while stopped
+	setTimeout(() => { fired = true }, 100)
+	`)
+	require.NoError(t, err)
+
+	msgs := b.subscriptions["tkd.events.v1.Event"]
+	require.NotNil(t, msgs)
+	require.Len(t, rt.core.scheduler.Entries(), 2)
+
+	rt.Stop()
+	require.False(t, rt.Running())
+
+	// subscriptions, schedules and timers are released
+	require.Empty(t, b.subscriptions)
+	require.Empty(t, rt.core.scheduler.Entries())
+
+	_, ok := <-msgs
+	require.False(t, ok, "subscription channel should be closed")
+
+	_, err = rt.RunScript("1")
+	require.ErrorIs(t, err, ErrEngineStopped)
+
+	// schedules may be removed while the engine is stopped
+	rt.core.clearSchedule(2)
+
+	// stopping twice is a no-op
+	rt.Stop()
+
+	rt.Start()
+	require.True(t, rt.Running())
+
+	// handlers and remaining schedules are restored
+	require.NotNil(t, b.subscriptions["tkd.events.v1.Event"])
+	require.NotEqual(t, msgs, b.subscriptions["tkd.events.v1.Event"])
+	require.Len(t, rt.core.scheduler.Entries(), 1)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	b.subscriptions["tkd.events.v1.Event"] <- &eventsv1.Event{Event: payload}
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled after restart")
+	}
+
+	// the timer has been cancelled by Stop
+	time.Sleep(200 * time.Millisecond)
+
+	value, err := rt.RunScript("fired")
+	require.NoError(t, err)
+	require.False(t, value.ToBoolean())
+
+	rt.Restart()
+	require.True(t, rt.Running())
+	require.Len(t, rt.core.scheduler.Entries(), 1)
+
+	rt.Stop()
+}
+
+type recordingPrinter struct {
+	lock sync.Mutex
+	msgs []string
+}
+
+func (p *recordingPrinter) record(msg string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.msgs = append(p.msgs, msg)
+}
+
+func (p *recordingPrinter) Log(msg string)   { p.record(msg) }
+func (p *recordingPrinter) Warn(msg string)  { p.record(msg) }
+func (p *recordingPrinter) Error(msg string) { p.record(msg) }
+
+func (p *recordingPrinter) messages() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]string(nil), p.msgs...)
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	b := &mockBroker{}
+	dlq := &memoryDeadLetters{letters: make(chan DeadLetter, 1)}
+	printer := &recordingPrinter{}
+
+	cfg := config.Config{
+		AutomationHandlerTimeout:       100 * time.Millisecond,
+		AutomationHandlerWarnThreshold: 20 * time.Millisecond,
+	}
+
+	rt, err := New("timeout", cfg, b, WithDeadLetterQueue(dlq), WithConsole(printer))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	var mode = "loop";
+
+	on("tkd.events.v1.Event", () => {
+		const start = Date.now();
+
+		switch (mode) {
+		case "loop":
+			while (true) {}
+		case "slow":
+			while (Date.now() - start < 50) {}
+		}
+	})
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	evt := &eventsv1.Event{Event: payload}
+	b.subscriptions["tkd.events.v1.Event"] <- evt
+
+	// the endless loop is interrupted and reported as a failure
+	letter := <-dlq.letters
+	require.Contains(t, letter.Error, ErrHandlerTimeout.Error())
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.AutomationTimeouts.WithLabelValues("timeout", "event")))
+
+	// the engine is still usable
+	value, err := rt.RunScript("1 + 1")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), value.ToInteger())
+
+	err = rt.Redeliver(letter.HandlerID, letter.Event)
+	require.ErrorIs(t, err, ErrHandlerTimeout)
+
+	// slow handlers succeed but are reported
+	_, err = rt.RunScript(`mode = "slow"`)
+	require.NoError(t, err)
+
+	require.NoError(t, rt.Redeliver(letter.HandlerID, letter.Event))
+
+	msgs := printer.messages()
+	require.Len(t, msgs, 3)
+	require.Contains(t, msgs[0], "interrupted after")
+	require.Contains(t, msgs[2], "exceeds the warning threshold")
+}
+
+func TestHandlerTimeoutAsync(t *testing.T) {
+	b := &mockBroker{}
+	dlq := &memoryDeadLetters{letters: make(chan DeadLetter, 1)}
+	printer := &recordingPrinter{}
+
+	cfg := config.Config{
+		AutomationHandlerTimeout: 100 * time.Millisecond,
+	}
+
+	rt, err := New("timeout-async", cfg, b, WithDeadLetterQueue(dlq), WithConsole(printer), func(e *Engine) {
+		e.Run(func(r *goja.Runtime) (goja.Value, error) {
+			r.Set("later", func() *goja.Promise {
+				promise, resolve, _ := r.NewPromise()
+
+				go e.EventLoop().RunOnLoop(func(*goja.Runtime) {
+					resolve(nil)
+				})
+
+				return promise
+			})
+			return nil, nil
+		})
+	})
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	on("tkd.events.v1.Event", async () => {
+		await later()
+		while (true) {}
+	})
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	b.subscriptions["tkd.events.v1.Event"] <- &eventsv1.Event{Event: payload}
+
+	// the continuation of the handler is interrupted
+	select {
+	case letter := <-dlq.letters:
+		require.Contains(t, letter.Error, ErrHandlerTimeout.Error())
+	case <-time.After(time.Second):
+		t.Fatal("async handler has not been interrupted")
+	}
+
+	// timer callbacks are interrupted as well
+	_, err = rt.RunScript(`setTimeout(() => { while (true) {} }, 0)`)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		for _, msg := range printer.messages() {
+			if strings.Contains(msg, "setTimeout callback interrupted") {
+				return true
+			}
+		}
+
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// the engine is still usable
+	value, err := rt.RunScript("1 + 1")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), value.ToInteger())
+}
+
+func TestLimits(t *testing.T) {
+	cfg := config.Config{
+		AutomationLimits: config.AutomationLimits{
+			Timers:          2,
+			Schedules:       1,
+			ConcurrentCalls: 1,
+			Subscriptions:   5,
+		},
+	}
+
+	// bundles may only lower the limits of the service
+	rt, err := New("limits", cfg, &mockBroker{}, WithAutomationConfig(modules.AutomationAnnotation{
+		Limits: config.AutomationLimits{
+			Timers:        10,
+			Subscriptions: 1,
+		},
+	}))
+	require.NoError(t, err)
+	defer rt.Stop()
+
+	require.Equal(t, 2, rt.Limits().Timers)
+	require.Equal(t, 1, rt.Limits().Subscriptions)
+
+	_, err = rt.RunScript(`on("tkd.events.v1.A", () => {})`)
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`on("tkd.events.v1.B", () => {})`)
+	require.ErrorContains(t, err, modules.ErrLimitExceeded.Error())
+
+	_, err = rt.RunScript(`schedule("* * * * *", () => {})`)
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`schedule("* * * * *", () => {})`)
+	require.ErrorContains(t, err, modules.ErrLimitExceeded.Error())
+
+	// timers are released once they fired or have been cleared
+	_, err = rt.RunScript(`
+	var fired = false;
+	var interval = setInterval(() => {}, 1000);
+	setTimeout(() => { fired = true }, 10);
+	`)
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`setTimeout(() => {}, 10)`)
+	require.ErrorContains(t, err, modules.ErrLimitExceeded.Error())
+
+	require.Eventually(t, func() bool {
+		value, err := rt.RunScript("fired")
+		return err == nil && value.ToBoolean()
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = rt.RunScript(`clearInterval(interval); setTimeout(() => {}, 10); setInterval(() => {}, 10)`)
+	require.NoError(t, err)
+
+	// outbound calls
+	release, err := rt.AcquireCall()
+	require.NoError(t, err)
+
+	_, err = rt.AcquireCall()
+	require.ErrorIs(t, err, modules.ErrLimitExceeded)
+
+	release()
+
+	release, err = rt.AcquireCall()
+	require.NoError(t, err)
+	release()
+}
+
+func TestAsyncHandlers(t *testing.T) {
+	b := &mockBroker{}
+	dlq := &memoryDeadLetters{letters: make(chan DeadLetter, 1)}
+
+	cfg := config.Config{
+		AutomationHandlerTimeout: 500 * time.Millisecond,
+	}
+
+	rt, err := New("async", cfg, b, WithDeadLetterQueue(dlq))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+	var mode = "fail";
+	var handled = 0;
+
+	const sleep = (ms) => new Promise(resolve => setTimeout(resolve, ms));
+
+	on("tkd.events.v1.Event", async () => {
+		await sleep(10);
+
+		switch (mode) {
+		case "fail":
+			throw new Error("async boom");
+		case "hang":
+			await new Promise(() => {});
+		}
+
+		handled++;
+	})
+	`)
+	require.NoError(t, err)
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	b.subscriptions["tkd.events.v1.Event"] <- &eventsv1.Event{Event: payload}
+
+	// rejections are handled like exceptions
+	letter := <-dlq.letters
+	require.Contains(t, letter.Error, "async boom")
+	require.NotEmpty(t, letter.Stack)
+
+	err = rt.Redeliver(letter.HandlerID, letter.Event)
+	var rejection *RejectionError
+	require.ErrorAs(t, err, &rejection)
+
+	// redelivering waits for the promise to resolve
+	_, err = rt.RunScript(`mode = "ok"`)
+	require.NoError(t, err)
+
+	require.NoError(t, rt.Redeliver(letter.HandlerID, letter.Event))
+
+	value, err := rt.RunScript("handled")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), value.ToInteger())
+
+	// pending promises are subject to the handler timeout
+	_, err = rt.RunScript(`mode = "hang"`)
+	require.NoError(t, err)
+
+	err = rt.Redeliver(letter.HandlerID, letter.Event)
+	require.ErrorIs(t, err, ErrHandlerTimeout)
+
+	// and are given up once the engine is stopped
+	result := make(chan error, 1)
+	go func() {
+		result <- rt.Redeliver(letter.HandlerID, letter.Event)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	rt.Stop()
+
+	require.ErrorIs(t, <-result, ErrEngineStopped)
+}
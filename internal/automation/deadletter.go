@@ -0,0 +1,65 @@
+package automation
+
+import (
+	"errors"
+
+	"github.com/dop251/goja"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+)
+
+// ErrNoHandler is returned by Engine.Redeliver if the engine does not have
+// a matching event handler.
+var ErrNoHandler = errors.New("no matching event handler")
+
+// DeadLetter describes an event that could not be handled by an automation.
+type DeadLetter struct {
+	// Bundle is the name of the automation engine.
+	Bundle string
+
+	// Subscription is the event type that has been passed to on().
+	Subscription string
+
+	// Handler is the index of the on() handler within the engine.
+	Handler int
+
+	// HandlerID identifies the on() handler by its subscription and source
+	// code and is used to find the handler when re-driving the event.
+	HandlerID string
+
+	// Event is the event that failed.
+	Event *eventsv1.Event
+
+	// Error is the error message and Stack the JavaScript stack trace, if
+	// available.
+	Error string
+	Stack string
+}
+
+// DeadLetterQueue stores events that could not be handled by an automation.
+type DeadLetterQueue interface {
+	Put(DeadLetter) error
+}
+
+// WithDeadLetterQueue configures a queue for events that failed to be converted
+// or whose handler threw an exception.
+func WithDeadLetterQueue(q DeadLetterQueue) EngineOption {
+	return func(e *Engine) {
+		e.deadLetters = q
+	}
+}
+
+// ErrorDetails returns the error message and, for JavaScript exceptions and
+// rejected promises, the stack trace of err.
+func ErrorDetails(err error) (string, string) {
+	var ex *goja.Exception
+	if errors.As(err, &ex) {
+		return ex.Error(), ex.String()
+	}
+
+	var rejection *RejectionError
+	if errors.As(err, &rejection) {
+		return rejection.Error(), rejection.Stack
+	}
+
+	return err.Error(), ""
+}
@@ -1,21 +1,37 @@
 package automation
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/dop251/goja"
 	"github.com/dop251/goja_nodejs/console"
 	"github.com/dop251/goja_nodejs/eventloop"
 	"github.com/dop251/goja_nodejs/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/noopdiscover"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/transpile"
 	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/leader"
+	"github.com/tierklinik-dobersberg/events-service/internal/storage"
 	"github.com/tierklinik-dobersberg/pbtype-server/pkg/protoresolve"
 	"github.com/tierklinik-dobersberg/pbtype-server/pkg/resolver"
 )
 
+// ErrEngineStopped is returned when trying to execute code on an engine
+// that has been stopped.
+var ErrEngineStopped = errors.New("automation engine stopped")
+
+// ErrHandlerTimeout is returned for handlers that have been interrupted
+// because they exceeded their timeout.
+var ErrHandlerTimeout = errors.New("automation handler timed out")
+
 type Engine struct {
 	name             string
 	loop             *eventloop.EventLoop
@@ -27,8 +43,29 @@ type Engine struct {
 	discoverer       discovery.Discoverer
 	resolver         protoresolve.Resolver
 	automationConfig modules.AutomationAnnotation
+	elector          leader.Elector
+	deadLetters      DeadLetterQueue
+	storage          *storage.Store
+	console          console.Printer
 	log              *slog.Logger
 
+	// ctx is the context of the handler that is currently executing on the
+	// event loop. It is only accessed on the event loop and captured by
+	// timers and promise reactions so it is restored whenever code of the
+	// handler resumes.
+	ctx context.Context
+
+	core *CoreModule
+
+	// lifecycleLock serializes Start and Stop
+	lifecycleLock sync.Mutex
+	running       bool
+
+	// callLock protects the number of concurrent outbound calls.
+	callLock       sync.Mutex
+	calls          int
+	callGeneration int
+
 	moduleRegistry *modules.Registry
 }
 
@@ -36,6 +73,17 @@ func (e *Engine) Registry() *require.Registry {
 	return e.registry
 }
 
+// Name returns the name of the engine.
+func (e *Engine) Name() string {
+	return e.name
+}
+
+// Storage returns the persistent key/value store or nil if none is
+// configured.
+func (e *Engine) Storage() *storage.Store {
+	return e.storage
+}
+
 func (e *Engine) Config() config.Config {
 	return e.cfg
 }
@@ -56,6 +104,22 @@ func WithDiscoverer(disc discovery.Discoverer) EngineOption {
 	}
 }
 
+// WithLeaderElector configures the leader elector that decides whether
+// scheduled callbacks are executed by this engine. Defaults to leader.Always.
+func WithLeaderElector(elector leader.Elector) EngineOption {
+	return func(e *Engine) {
+		e.elector = elector
+	}
+}
+
+// WithStorage configures the persistent key/value store that is available
+// to scripts using the storage module.
+func WithStorage(store *storage.Store) EngineOption {
+	return func(e *Engine) {
+		e.storage = store
+	}
+}
+
 func WithSourceLoader(ldr require.SourceLoader) EngineOption {
 	return func(e *Engine) {
 		e.ldr = ldr
@@ -82,18 +146,37 @@ func WithAutomationConfig(cfg modules.AutomationAnnotation) EngineOption {
 
 func WithConsole(printer console.Printer) EngineOption {
 	return func(e *Engine) {
-		obj := e.Runtime().NewObject()
-		exports := e.Runtime().NewObject()
-
-		obj.Set("exports", exports)
-		console := console.RequireWithPrinter(printer)
+		e.console = printer
 
-		console(e.Runtime(), obj)
+		e.Run(func(r *goja.Runtime) (goja.Value, error) {
+			setConsole(r, printer)
 
-		e.Runtime().Set("console", exports)
+			return nil, nil
+		})
 	}
 }
 
+func setConsole(r *goja.Runtime, printer console.Printer) {
+	obj := r.NewObject()
+	exports := r.NewObject()
+
+	obj.Set("exports", exports)
+	console := console.RequireWithPrinter(printer)
+
+	console(r, obj)
+
+	r.Set("console", exports)
+}
+
+// logPrinter is a console.Printer that writes to a slog.Logger.
+type logPrinter struct {
+	log *slog.Logger
+}
+
+func (p *logPrinter) Log(msg string)   { p.log.Info(msg) }
+func (p *logPrinter) Warn(msg string)  { p.log.Warn(msg) }
+func (p *logPrinter) Error(msg string) { p.log.Error(msg) }
+
 func New(name string, cfg config.Config, broker Broker, opts ...EngineOption) (*Engine, error) {
 	engine := &Engine{
 		cfg:            cfg,
@@ -101,18 +184,31 @@ func New(name string, cfg config.Config, broker Broker, opts ...EngineOption) (*
 		ldr:            require.DefaultSourceLoader,
 		moduleRegistry: modules.DefaultRegistry,
 		discoverer:     &noopdiscover.NoOpDiscoverer{},
+		elector:        leader.Always{},
 		resolver:       protoresolve.NewGlobalResolver(),
+		ctx:            context.Background(),
 		log: slog.Default().With(
 			slog.String("automation", name),
 		),
 	}
 
+	engine.console = &logPrinter{log: engine.log}
+
 	registry := require.NewRegistry(require.WithLoader(func(path string) ([]byte, error) {
 		if engine.baseDir != "" {
 			path = filepath.Join(engine.baseDir, path)
 		}
 
-		return engine.ldr(path)
+		source, err := transpile.Loader(engine.ldr)(path)
+
+		// compile errors are reported to the console so they show up in the
+		// bundle logs even if the failed require is caught by the script.
+		var compileErr *transpile.Error
+		if errors.As(err, &compileErr) {
+			engine.console.Error(compileErr.Error())
+		}
+
+		return source, err
 	}))
 
 	loop := eventloop.NewEventLoop(eventloop.WithRegistry(registry), eventloop.EnableConsole(false))
@@ -130,23 +226,29 @@ func New(name string, cfg config.Config, broker Broker, opts ...EngineOption) (*
 
 	// prepare and enalbe the core-module
 	core := NewCoreModule(engine, broker)
+	engine.core = core
 
 	loop.Run(func(r *goja.Runtime) {
 		engine.rt = r
 
 		r.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+		r.SetAsyncContextTracker(&contextTracker{engine: engine})
 
 		core.Enable(r)
+
+		// provide a default console that writes to the engine logger. It may be
+		// replaced using WithConsole.
+		setConsole(r, engine.console)
 	})
 
+	// start the loop before applying any engine options
+	engine.Start()
+
 	// Apply any engine options
 	for _, opt := range opts {
 		opt(engine)
 	}
 
-	// start the loop before applying any engine options
-	engine.loop.Start()
-
 	// load all modules from the module registry
 	if engine.moduleRegistry != nil {
 		if _, err := engine.moduleRegistry.EnableModules(engine); err != nil {
@@ -157,10 +259,36 @@ func New(name string, cfg config.Config, broker Broker, opts ...EngineOption) (*
 	return engine, nil
 }
 
+// HasModule reports whether name is a native module provided by the
+// engine's module registry.
+func (e *Engine) HasModule(name string) bool {
+	return e.moduleRegistry != nil && e.moduleRegistry.Has(name)
+}
+
 func (e *Engine) AutomationConfig() modules.AutomationAnnotation {
 	return e.automationConfig
 }
 
+// HandlerTimeout returns the maximum execution time of a handler. Zero
+// disables the timeout.
+func (e *Engine) HandlerTimeout() time.Duration {
+	if e.automationConfig.Timeout > 0 {
+		return time.Duration(e.automationConfig.Timeout)
+	}
+
+	return e.cfg.AutomationHandlerTimeout
+}
+
+// HandlerWarnThreshold returns the execution time of a handler after which
+// a warning is logged. Zero disables the warning.
+func (e *Engine) HandlerWarnThreshold() time.Duration {
+	if e.automationConfig.WarnThreshold > 0 {
+		return time.Duration(e.automationConfig.WarnThreshold)
+	}
+
+	return e.cfg.AutomationHandlerWarnThreshold
+}
+
 func (e *Engine) Log() *slog.Logger {
 	return e.log
 }
@@ -169,6 +297,11 @@ func (e *Engine) EventLoop() *eventloop.EventLoop {
 	return e.loop
 }
 
+// LeaderElector returns the leader elector used for scheduled callbacks.
+func (e *Engine) LeaderElector() leader.Elector {
+	return e.elector
+}
+
 func (e *Engine) Discoverer() discovery.Discoverer {
 	return e.discoverer
 }
@@ -177,36 +310,118 @@ func (e *Engine) TypeResolver() protoresolve.Resolver {
 	return e.resolver
 }
 
+// Context returns the context of the handler that is currently executing.
+// It must only be called on the event loop.
+func (e *Engine) Context() context.Context {
+	return e.ctx
+}
+
+// enter makes ctx the context of the code that is executing on the event
+// loop and returns a function that restores the previous one. It must be
+// called on the event loop.
+func (e *Engine) enter(ctx context.Context) func() {
+	previous := e.ctx
+	e.ctx = ctx
+
+	return func() {
+		e.ctx = previous
+	}
+}
+
+// Run executes fn on the event loop and waits for it to return. It returns
+// ErrEngineStopped if the engine has been stopped.
 func (e *Engine) Run(fn func(*goja.Runtime) (goja.Value, error)) (goja.Value, error) {
 	errCh := make(chan error, 1)
 	valueChan := make(chan goja.Value, 1)
 
-	e.loop.RunOnLoop(func(r *goja.Runtime) {
+	if !e.loop.RunOnLoop(func(r *goja.Runtime) {
 		value, err := fn(r)
 
 		valueChan <- value
 		errCh <- err
-	})
+	}) {
+		return nil, ErrEngineStopped
+	}
 
 	return <-valueChan, <-errCh
 }
 
+// RunScript executes script on the event loop and waits for it to return.
+// It returns ErrEngineStopped if the engine has been stopped.
 func (e *Engine) RunScript(script string) (goja.Value, error) {
-	errCh := make(chan error, 1)
-	valueChan := make(chan goja.Value, 1)
+	return e.Run(func(r *goja.Runtime) (goja.Value, error) {
+		return r.RunScript("", script)
+	})
+}
 
-	e.loop.RunOnLoop(func(r *goja.Runtime) {
-		value, err := r.RunScript("", script)
+// Redeliver executes the on() handler identified by handlerID (see
+// DeadLetter.HandlerID) for evt and returns any error thrown by the handler.
+// The event is not put into the dead-letter queue again.
+func (e *Engine) Redeliver(handlerID string, evt *eventsv1.Event) error {
+	return e.core.redeliver(handlerID, evt)
+}
 
-		valueChan <- value
-		errCh <- err
-	})
+// Start starts the event loop, subscribes all event handlers registered
+// using on() and starts all schedules. Engines are started by New, Start is
+// only required to resume an engine after Stop.
+func (e *Engine) Start() {
+	e.lifecycleLock.Lock()
+	defer e.lifecycleLock.Unlock()
 
-	return <-valueChan, <-errCh
+	if e.running {
+		return
+	}
+
+	e.loop.Start()
+	e.core.start()
+
+	e.running = true
+
+	e.log.Info("automation engine started")
+}
+
+// Stop unsubscribes all event handlers, removes all schedules and
+// terminates the event loop, cancelling all timers and intervals. Queued
+// events are dropped. The handlers and schedules are kept and restored by
+// Start. Stop must not be called from the event loop.
+func (e *Engine) Stop() {
+	e.lifecycleLock.Lock()
+	defer e.lifecycleLock.Unlock()
+
+	e.stopLocked()
 }
 
-func (e *Engine) Stop() int {
-	return e.loop.Stop()
+// stopLocked stops the engine. e.lifecycleLock must be held.
+func (e *Engine) stopLocked() {
+	if !e.running {
+		return
+	}
+
+	e.core.stop()
+	e.loop.Terminate()
+
+	// all timers have been cancelled and outbound calls are not reported
+	// back anymore.
+	e.core.timers = make(map[any]struct{})
+	e.resetCalls()
+
+	e.running = false
+
+	e.log.Info("automation engine stopped")
+}
+
+// Restart stops and starts the engine.
+func (e *Engine) Restart() {
+	e.Stop()
+	e.Start()
+}
+
+// Running reports whether the engine is started.
+func (e *Engine) Running() bool {
+	e.lifecycleLock.Lock()
+	defer e.lifecycleLock.Unlock()
+
+	return e.running
 }
 
 // Compile time check
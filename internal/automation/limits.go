@@ -0,0 +1,135 @@
+package automation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/common"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+)
+
+// Limits returns the resource limits of the engine.
+func (e *Engine) Limits() config.AutomationLimits {
+	return e.automationConfig.Limits.Cap(e.cfg.AutomationLimits)
+}
+
+// AcquireCall implements modules.VU.
+func (e *Engine) AcquireCall() (func(), error) {
+	e.callLock.Lock()
+	defer e.callLock.Unlock()
+
+	if limit := e.Limits().ConcurrentCalls; limit > 0 && e.calls >= limit {
+		return nil, fmt.Errorf("%w: at most %d concurrent calls", modules.ErrLimitExceeded, limit)
+	}
+
+	e.calls++
+
+	// calls that are still running when the engine is stopped are not
+	// released anymore once it has been started again.
+	generation := e.callGeneration
+
+	return func() {
+		e.callLock.Lock()
+		defer e.callLock.Unlock()
+
+		if e.callGeneration == generation {
+			e.calls--
+		}
+	}, nil
+}
+
+// resetCalls forgets about all outbound calls.
+func (e *Engine) resetCalls() {
+	e.callLock.Lock()
+	defer e.callLock.Unlock()
+
+	e.calls = 0
+	e.callGeneration++
+}
+
+// enableTimers replaces setTimeout, setInterval, clearTimeout and
+// clearInterval of the event loop with versions that enforce the timer
+// limit. It must be called on the event loop.
+func (c *CoreModule) enableTimers(r *goja.Runtime) {
+	wrap := func(name string, repeating bool) {
+		set, ok := goja.AssertFunction(r.Get(name))
+		if !ok {
+			return
+		}
+
+		r.Set(name, func(call goja.FunctionCall) goja.Value {
+			if limit := c.engine.Limits().Timers; limit > 0 && len(c.timers) >= limit {
+				common.Throw(r, fmt.Errorf("%w: at most %d active timers", modules.ErrLimitExceeded, limit))
+			}
+
+			fn, ok := goja.AssertFunction(call.Argument(0))
+			if !ok {
+				return goja.Undefined()
+			}
+
+			var handle any
+
+			// the callback continues the handler that created the timer
+			ctx := c.engine.Context()
+
+			args := append([]goja.Value{
+				r.ToValue(func(inner goja.FunctionCall) goja.Value {
+					if !repeating {
+						delete(c.timers, handle)
+					}
+
+					restore := c.engine.enter(ctx)
+					defer restore()
+
+					stop := c.guard()
+					_, err := fn(goja.Undefined(), inner.Arguments...)
+					stop()
+
+					if errors.Is(err, ErrHandlerTimeout) {
+						c.engine.console.Error(fmt.Sprintf("%s callback interrupted: %s", name, err))
+					}
+
+					if err != nil {
+						c.engine.log.Error("failed to execute timer callback", "error", err)
+					}
+
+					return goja.Undefined()
+				}),
+			}, call.Arguments[1:]...)
+
+			value, err := set(goja.Undefined(), args...)
+			if err != nil {
+				common.Throw(r, err)
+			}
+
+			handle = value.Export()
+			c.timers[handle] = struct{}{}
+
+			return value
+		})
+	}
+
+	unwrap := func(name string) {
+		cancel, ok := goja.AssertFunction(r.Get(name))
+		if !ok {
+			return
+		}
+
+		r.Set(name, func(call goja.FunctionCall) goja.Value {
+			delete(c.timers, call.Argument(0).Export())
+
+			if _, err := cancel(goja.Undefined(), call.Arguments...); err != nil {
+				common.Throw(r, err)
+			}
+
+			return goja.Undefined()
+		})
+	}
+
+	wrap("setTimeout", false)
+	wrap("setInterval", true)
+	unwrap("clearTimeout")
+	unwrap("clearInterval")
+}
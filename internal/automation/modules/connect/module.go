@@ -18,7 +18,11 @@ import (
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/common"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
 	"github.com/tierklinik-dobersberg/pbtype-server/pkg/protoresolve"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -90,6 +94,7 @@ func makeServiceClient(disc discovery.Discoverer, resolver protoresolve.Resolver
 			headers:  cfg.ConnectHeaders,
 			resolver: resolver,
 			log:      vu.Log(),
+			vu:       vu,
 		}
 
 		serviceObj.Set(methodName, cli.do)
@@ -107,6 +112,7 @@ type client struct {
 	headers  map[string]string
 	resolver protoresolve.Resolver
 	log      *slog.Logger
+	vu       modules.VU
 
 	rt *goja.Runtime
 
@@ -160,9 +166,19 @@ func (c *client) do(in *goja.Object, options *goja.Object) any {
 		common.Throw(c.rt, err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, ep, bytes.NewReader(blob))
+	ctx, span := tracing.Tracer().Start(c.vu.Context(), c.service+"/"+c.method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "connect_rpc"),
+			attribute.String("rpc.service", c.service),
+			attribute.String("rpc.method", c.method),
+		),
+	)
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep, bytes.NewReader(blob))
 	if err != nil {
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -207,43 +223,62 @@ func (c *client) do(in *goja.Object, options *goja.Object) any {
 
 	}
 
+	// propagate the trace of the automation handler
+	tracing.InjectHeader(ctx, req.Header)
+
+	release, err := c.vu.AcquireCall()
+	if err != nil {
+		c.throw(span, err)
+	}
+	defer release()
+
 	response, err := c.cli.Do(req)
 	if err != nil {
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 	defer response.Body.Close()
 
+	span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+
 	if response.StatusCode != 200 {
 		err := fmt.Errorf("unexpected status code for %s %s: %s", req.Method, req.URL.String(), response.Status)
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 
 	res := dynamicpb.NewMessage(c.response)
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 
 	if err := protojson.Unmarshal(body, res); err != nil {
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 
 	// create a goja value
 
 	protoBlob, err := protojson.Marshal(res)
 	if err != nil {
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 
 	m := make(map[string]any)
 	if err := json.Unmarshal(protoBlob, &m); err != nil {
-		common.Throw(c.rt, err)
+		c.throw(span, err)
 	}
 
 	return m
 }
 
+// throw records err on span and throws it as a JavaScript exception.
+func (c *client) throw(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	common.Throw(c.rt, err)
+}
+
 func ObjectToProto(in *goja.Object, out protoreflect.MessageDescriptor, resolver protoresolve.Resolver) (proto.Message, error) {
 	msg := dynamicpb.NewMessage(out)
 
@@ -4,6 +4,7 @@ import (
 	"github.com/dop251/goja"
 	"github.com/elazarl/goproxy"
 	"github.com/olebedev/gojax/fetch"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/common"
 	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
 )
 
@@ -16,5 +17,51 @@ func (*Module) NewModuleInstance(vu modules.VU) (*goja.Object, error) {
 		return nil, err
 	}
 
+	vu.EventLoop().RunOnLoop(func(r *goja.Runtime) {
+		limitConcurrency(r, vu)
+	})
+
 	return nil, nil
 }
+
+// limitConcurrency wraps fetch so each request holds one of the concurrent
+// outbound calls of the bundle until the returned promise is settled.
+func limitConcurrency(r *goja.Runtime, vu modules.VU) {
+	fetch, ok := goja.AssertFunction(r.Get("fetch"))
+	if !ok {
+		return
+	}
+
+	r.Set("fetch", func(call goja.FunctionCall) goja.Value {
+		release, err := vu.AcquireCall()
+		if err != nil {
+			common.Throw(r, err)
+		}
+
+		value, err := fetch(goja.Undefined(), call.Arguments...)
+		if err != nil {
+			release()
+			common.Throw(r, err)
+		}
+
+		promise := value.ToObject(r)
+
+		then, ok := goja.AssertFunction(promise.Get("then"))
+		if !ok {
+			release()
+			return value
+		}
+
+		settled := r.ToValue(func(goja.FunctionCall) goja.Value {
+			release()
+			return goja.Undefined()
+		})
+
+		if _, err := then(promise, settled, settled); err != nil {
+			release()
+			common.Throw(r, err)
+		}
+
+		return value
+	})
+}
@@ -1,13 +1,18 @@
 package modules
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/dop251/goja"
 	"github.com/dop251/goja_nodejs/eventloop"
 	"github.com/dop251/goja_nodejs/require"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery"
 	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/storage"
 	"github.com/tierklinik-dobersberg/pbtype-server/pkg/protoresolve"
 )
 
@@ -21,6 +26,45 @@ type AutomationAnnotation struct {
 	// WrapInOpertaion can be set to true to wrap any event and schedule
 	// callbacks in long-running operations.
 	WrapInOperation bool `json:"wrapInOperation"`
+
+	// Timeout overwrites the maximum execution time of a single event or
+	// schedule handler, e.g. "1m". Handlers that exceed it are interrupted.
+	Timeout Duration `json:"timeout"`
+
+	// WarnThreshold overwrites the execution time of a handler after which
+	// a warning is logged.
+	WarnThreshold Duration `json:"warnThreshold"`
+
+	// Limits lowers the resource limits of the bundle. Limits above the
+	// ones configured for the service are ignored.
+	Limits config.AutomationLimits `json:"limits"`
+}
+
+// ErrLimitExceeded is returned if a bundle exceeds one of its resource
+// limits.
+var ErrLimitExceeded = errors.New("resource limit exceeded")
+
+// Duration is a time.Duration that is encoded as a string in JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(blob []byte) error {
+	var s string
+	if err := json.Unmarshal(blob, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
 }
 
 type VU interface {
@@ -61,6 +105,24 @@ type VU interface {
 	// TypeResolver returns the protobuf type resolver which is either backed
 	// by protoregistry or by using a pbtype-server instance.
 	TypeResolver() protoresolve.Resolver
+
+	// Context returns the context of the automation handler that is
+	// currently executing, including its timers and the continuations of its
+	// promises. It carries the trace of the handler and must only be used on
+	// the event loop.
+	Context() context.Context
+
+	// AcquireCall reserves one of the concurrent outbound calls of the
+	// bundle. It returns ErrLimitExceeded if all are in use. The returned
+	// function releases the call again.
+	AcquireCall() (func(), error)
+
+	// Name returns the name of the automation bundle.
+	Name() string
+
+	// Storage returns the persistent key/value store or nil if none is
+	// configured.
+	Storage() *storage.Store
 }
 
 type Module interface {
@@ -37,6 +37,16 @@ func (reg *Registry) Register(mod Module) error {
 	return nil
 }
 
+// Has reports whether a module with name is registered.
+func (reg *Registry) Has(name string) bool {
+	reg.lock.RLock()
+	defer reg.lock.RUnlock()
+
+	_, ok := reg.modules[name]
+
+	return ok
+}
+
 type Instance struct {
 	Name   string
 	Module *goja.Object
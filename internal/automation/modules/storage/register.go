@@ -0,0 +1,7 @@
+package storage
+
+import "github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+
+func init() {
+	modules.Register(&Module{})
+}
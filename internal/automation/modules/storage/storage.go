@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/common"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+	kvstore "github.com/tierklinik-dobersberg/events-service/internal/storage"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrNotConfigured is thrown if the storage module is used without a
+// configured STORAGE_PATH.
+var ErrNotConfigured = errors.New("storage is not configured")
+
+// Storage module
+type Instance struct {
+	rt     *goja.Runtime
+	store  *kvstore.Store
+	bundle string
+}
+
+type Module struct{}
+
+func (*Module) Name() string { return "storage" }
+
+func (*Module) NewModuleInstance(vu modules.VU) (*goja.Object, error) {
+	mod := &Instance{
+		rt:     vu.Runtime(),
+		store:  vu.Storage(),
+		bundle: kvstore.Namespace(vu.Name()),
+	}
+
+	obj := vu.Runtime().NewObject()
+
+	obj.Set("get", mod.get)
+	obj.Set("set", mod.set)
+	obj.Set("delete", mod.delete)
+	obj.Set("list", mod.list)
+	obj.Set("compareAndSet", mod.compareAndSet)
+
+	return obj, nil
+}
+
+// get returns the value stored for key or undefined.
+func (m *Instance) get(key string) goja.Value {
+	m.checkConfigured()
+
+	value, err := m.store.Get(m.bundle, key)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrNotFound) {
+			return goja.Undefined()
+		}
+
+		common.Throw(m.rt, err)
+	}
+
+	return m.rt.ToValue(value.AsInterface())
+}
+
+// set stores value for key. options may specify a ttl as milliseconds or a
+// duration string, e.g. "1h".
+func (m *Instance) set(key string, value goja.Value, options *goja.Object) {
+	m.checkConfigured()
+
+	if err := m.store.Set(m.bundle, key, m.toProto(value), m.ttl(options)); err != nil {
+		common.Throw(m.rt, err)
+	}
+}
+
+// delete removes key and reports whether it existed.
+func (m *Instance) delete(key string) bool {
+	m.checkConfigured()
+
+	if err := m.store.Delete(m.bundle, key); err != nil {
+		if errors.Is(err, kvstore.ErrNotFound) {
+			return false
+		}
+
+		common.Throw(m.rt, err)
+	}
+
+	return true
+}
+
+// list returns all entries whose key starts with prefix.
+func (m *Instance) list(prefix string) []map[string]any {
+	m.checkConfigured()
+
+	entries, err := m.store.List(m.bundle, prefix)
+	if err != nil {
+		common.Throw(m.rt, err)
+	}
+
+	result := make([]map[string]any, len(entries))
+	for idx, entry := range entries {
+		var expires any
+		if entry.ExpireTime != nil {
+			expires = entry.ExpireTime.AsTime()
+		}
+
+		result[idx] = map[string]any{
+			"key":     entry.Key,
+			"value":   entry.Value.AsInterface(),
+			"expires": expires,
+		}
+	}
+
+	return result
+}
+
+// compareAndSet stores value for key if the current value equals expected
+// and reports whether it has been stored. If expected is undefined, the key
+// must not exist.
+func (m *Instance) compareAndSet(key string, expected goja.Value, value goja.Value, options *goja.Object) bool {
+	m.checkConfigured()
+
+	var old *structpb.Value
+	if expected != nil && !goja.IsUndefined(expected) {
+		old = m.toProto(expected)
+	}
+
+	swapped, err := m.store.CompareAndSet(m.bundle, key, old, m.toProto(value), m.ttl(options))
+	if err != nil {
+		common.Throw(m.rt, err)
+	}
+
+	return swapped
+}
+
+func (m *Instance) checkConfigured() {
+	if m.store == nil {
+		common.Throw(m.rt, ErrNotConfigured)
+	}
+}
+
+// toProto converts value to a protobuf value using its JSON representation.
+func (m *Instance) toProto(value goja.Value) *structpb.Value {
+	if common.IsNullish(value) {
+		return structpb.NewNullValue()
+	}
+
+	blob, err := json.Marshal(value.Export())
+	if err != nil {
+		common.Throw(m.rt, fmt.Errorf("failed to encode value: %w", err))
+	}
+
+	result := new(structpb.Value)
+	if err := protojson.Unmarshal(blob, result); err != nil {
+		common.Throw(m.rt, fmt.Errorf("failed to encode value: %w", err))
+	}
+
+	return result
+}
+
+func (m *Instance) ttl(options *goja.Object) time.Duration {
+	if options == nil {
+		return 0
+	}
+
+	value := options.Get("ttl")
+	if common.IsNullish(value) {
+		return 0
+	}
+
+	switch v := value.Export().(type) {
+	case string:
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			common.Throw(m.rt, fmt.Errorf("invalid ttl: %w", err))
+		}
+
+		return ttl
+
+	case int64:
+		return time.Duration(v) * time.Millisecond
+
+	case float64:
+		return time.Duration(v * float64(time.Millisecond))
+	}
+
+	common.Throw(m.rt, fmt.Errorf("invalid ttl: expected milliseconds or a duration string"))
+
+	return 0
+}
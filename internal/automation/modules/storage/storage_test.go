@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/modules"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	kvstore "github.com/tierklinik-dobersberg/events-service/internal/storage"
+)
+
+func TestStorageModule(t *testing.T) {
+	store, err := kvstore.Open(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	reg := &modules.Registry{}
+	reg.Register(&Module{})
+
+	rt, err := automation.New("/srv/automation/test.zip", config.Config{}, nil, automation.WithModulsRegistry(reg), automation.WithStorage(store))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`
+		const storage = require("storage")
+
+		if (storage.get("foo") !== undefined) {
+			throw new Error("expected missing key to be undefined")
+		}
+
+		storage.set("foo", { count: 1, tags: ["a"] })
+		storage.set("fizz", "buzz", { ttl: "1h" })
+
+		const value = storage.get("foo")
+		if (value.count !== 1 || value.tags[0] !== "a") {
+			throw new Error("unexpected value: " + JSON.stringify(value))
+		}
+
+		if (!storage.compareAndSet("counter", undefined, 1)) {
+			throw new Error("expected compareAndSet on a missing key to succeed")
+		}
+
+		if (storage.compareAndSet("counter", 2, 3)) {
+			throw new Error("expected compareAndSet with a stale value to fail")
+		}
+
+		if (!storage.compareAndSet("counter", 1, 2, { ttl: 60000 })) {
+			throw new Error("expected compareAndSet to succeed")
+		}
+
+		const entries = storage.list("f")
+		if (entries.length !== 2 || entries[0].key !== "fizz" || !entries[0].expires || entries[1].expires) {
+			throw new Error("unexpected entries: " + JSON.stringify(entries))
+		}
+
+		if (!storage.delete("foo") || storage.delete("foo")) {
+			throw new Error("unexpected result from delete")
+		}
+	`)
+	require.NoError(t, err)
+
+	// data is stored in the namespace of the bundle
+	value, err := store.Get("test", "counter")
+	require.NoError(t, err)
+	require.Equal(t, float64(2), value.GetNumberValue())
+}
+
+func TestStorageModuleNotConfigured(t *testing.T) {
+	reg := &modules.Registry{}
+	reg.Register(&Module{})
+
+	rt, err := automation.New("test", config.Config{}, nil, automation.WithModulsRegistry(reg))
+	require.NoError(t, err)
+
+	_, err = rt.RunScript(`require("storage").get("foo")`)
+	require.ErrorContains(t, err, ErrNotConfigured.Error())
+}
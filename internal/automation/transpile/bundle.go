@@ -0,0 +1,93 @@
+package transpile
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Bundle bundles the entry point at path and its dependency graph into a
+// single script that is wrapped in an immediately invoked function. ES
+// modules and TypeScript sources are transformed and packages from
+// node_modules are resolved using the module, main and exports fields of
+// their package.json. Bare imports for which external reports true are left
+// to be resolved by require at runtime, as are Node.js built-ins. Exports of
+// the entry point are ignored.
+func Bundle(path string, external func(name string) bool) ([]byte, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{abs},
+		AbsWorkingDir: filepath.Dir(abs),
+		Bundle:        true,
+		Format:        api.FormatIIFE,
+		Platform:      api.PlatformNode,
+		MainFields:    []string{"module", "main"},
+		Target:        target,
+		Sourcemap:     api.SourceMapExternal,
+		// the output is never written, the name is only used for the source
+		// map.
+		Outfile:  strings.TrimSuffix(abs, filepath.Ext(abs)) + ".bundle.js",
+		LogLevel: api.LogLevelSilent,
+		Plugins: []api.Plugin{
+			{
+				Name: "native-modules",
+				Setup: func(build api.PluginBuild) {
+					build.OnResolve(api.OnResolveOptions{Filter: `^[^./]`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+						if external != nil && external(args.Path) {
+							return api.OnResolveResult{Path: args.Path, External: true}, nil
+						}
+
+						// fall through to the default resolver
+						return api.OnResolveResult{}, nil
+					})
+				},
+			},
+		},
+	})
+
+	if len(result.Errors) > 0 {
+		return nil, newError(path, result.Errors)
+	}
+
+	var code, sourceMap []byte
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".map") {
+			sourceMap = file.Contents
+		} else {
+			code = file.Contents
+		}
+	}
+
+	return inlineSourceMap(code, sourceMap)
+}
+
+// inlineSourceMap appends sourceMap to code. goja refuses source maps
+// without any mappings, i.e. for entry points that are empty after tree
+// shaking, so those are dropped.
+func inlineSourceMap(code []byte, sourceMap []byte) ([]byte, error) {
+	if len(sourceMap) == 0 {
+		return code, nil
+	}
+
+	var parsed struct {
+		Mappings string `json:"mappings"`
+	}
+
+	if err := json.Unmarshal(sourceMap, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid source map: %w", err)
+	}
+
+	if parsed.Mappings == "" {
+		return code, nil
+	}
+
+	return fmt.Appendf(code, "//# sourceMappingURL=data:application/json;base64,%s\n", base64.StdEncoding.EncodeToString(sourceMap)), nil
+}
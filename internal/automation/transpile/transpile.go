@@ -0,0 +1,100 @@
+// Package transpile compiles TypeScript sources and ES modules to JavaScript
+// that can be executed by the automation engine.
+package transpile
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dop251/goja_nodejs/require"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// target is the language version scripts are compiled to.
+const target = api.ES2017
+
+// Error is returned if a source file fails to compile.
+type Error struct {
+	// Path is the path of the source file.
+	Path string
+
+	// Messages holds the compiler errors in the form file:line:column: text.
+	Messages []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("failed to compile %s: %s", e.Path, strings.Join(e.Messages, "; "))
+}
+
+// IsTypeScript reports whether path refers to a TypeScript source file.
+func IsTypeScript(path string) bool {
+	switch filepath.Ext(path) {
+	case ".ts", ".mts", ".cts":
+		return true
+	}
+
+	return false
+}
+
+// TypeScript transpiles the TypeScript source loaded from path to a CommonJS
+// script.
+func TypeScript(path string, source []byte) ([]byte, error) {
+	result := api.Transform(string(source), api.TransformOptions{
+		Loader:     api.LoaderTS,
+		Format:     api.FormatCommonJS,
+		Target:     target,
+		Sourcefile: path,
+		Sourcemap:  api.SourceMapExternal,
+		LogLevel:   api.LogLevelSilent,
+	})
+
+	if len(result.Errors) > 0 {
+		return nil, newError(path, result.Errors)
+	}
+
+	return inlineSourceMap(result.Code, result.Map)
+}
+
+// Loader returns a require.SourceLoader that transpiles TypeScript files
+// loaded by ldr. Requests for a missing .js file fall back to the .ts file
+// of the same name so TypeScript modules can be required without an
+// extension.
+func Loader(ldr require.SourceLoader) require.SourceLoader {
+	return func(path string) ([]byte, error) {
+		source, err := ldr(path)
+
+		if errors.Is(err, require.ModuleFileDoesNotExistError) && filepath.Ext(path) == ".js" {
+			path = strings.TrimSuffix(path, ".js") + ".ts"
+			source, err = ldr(path)
+		}
+
+		if err != nil || !IsTypeScript(path) {
+			return source, err
+		}
+
+		return TypeScript(path, source)
+	}
+}
+
+func newError(path string, msgs []api.Message) *Error {
+	err := &Error{
+		Path:     path,
+		Messages: make([]string, len(msgs)),
+	}
+
+	for idx, msg := range msgs {
+		err.Messages[idx] = formatMessage(path, msg)
+	}
+
+	return err
+}
+
+func formatMessage(path string, msg api.Message) string {
+	if msg.Location == nil {
+		return fmt.Sprintf("%s: %s", path, msg.Text)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", msg.Location.File, msg.Location.Line, msg.Location.Column+1, msg.Text)
+}
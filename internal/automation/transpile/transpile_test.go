@@ -0,0 +1,56 @@
+package transpile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dop251/goja_nodejs/require"
+	"github.com/stretchr/testify/assert"
+	testifyrequire "github.com/stretchr/testify/require"
+)
+
+func TestLoader(t *testing.T) {
+	files := map[string]string{
+		"plain.js": "module.exports = 1",
+		"typed.ts": "export const value: number = 1",
+		"types.ts": "declare const injected: number",
+	}
+
+	ldr := Loader(func(path string) ([]byte, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, require.ModuleFileDoesNotExistError
+		}
+
+		return []byte(content), nil
+	})
+
+	source, err := ldr("plain.js")
+	testifyrequire.NoError(t, err)
+	assert.Equal(t, files["plain.js"], string(source))
+
+	// missing .js files fall back to the TypeScript source
+	source, err = ldr("typed.js")
+	testifyrequire.NoError(t, err)
+	assert.Contains(t, string(source), "value = 1")
+	assert.NotContains(t, string(source), "number")
+
+	assert.Contains(t, string(source), "sourceMappingURL")
+
+	// modules that only contain declarations are empty and have no source map
+	source, err = ldr("types.ts")
+	testifyrequire.NoError(t, err)
+	assert.NotContains(t, string(source), "sourceMappingURL")
+
+	_, err = ldr("missing.js")
+	assert.ErrorIs(t, err, require.ModuleFileDoesNotExistError)
+}
+
+func TestTypeScriptError(t *testing.T) {
+	_, err := TypeScript("broken.ts", []byte("let x: = 1"))
+
+	var compileErr *Error
+	testifyrequire.True(t, errors.As(err, &compileErr))
+	testifyrequire.Len(t, compileErr.Messages, 1)
+	assert.Contains(t, compileErr.Messages[0], "broken.ts:1:8:")
+}
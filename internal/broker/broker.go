@@ -5,16 +5,30 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/metrics"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/singleflight"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
+// Wildcard may be passed to Broker.Subscribe to receive events of all types.
+const Wildcard = "#"
+
+// spoolBatchSize is the number of spooled events published at once while
+// flushing the spool. Publish is blocked while a batch is published.
+const spoolBatchSize = 100
+
 type BlockingMQTTClient interface {
 	Subscribe(string, byte, mqtt.MessageHandler) error
 	Unsubscribe(...string) error
@@ -22,8 +36,9 @@ type BlockingMQTTClient interface {
 }
 
 type Broker struct {
-	connLock sync.Mutex
-	conn     BlockingMQTTClient
+	connLock  sync.Mutex
+	conn      BlockingMQTTClient
+	connected atomic.Bool
 
 	l         sync.RWMutex
 	receivers map[string][]chan *eventsv1.Event
@@ -31,23 +46,71 @@ type Broker struct {
 
 	single singleflight.Group
 
-	retainedMsgs map[string]*eventsv1.Event
+	// retainedMsgs holds the retained events by type URL and entity key.
+	// Types without a configured key use an empty key.
+	retainedMsgs map[string]map[string]*eventsv1.Event
+	retainedKeys map[string]string
+	resolver     TypeResolver
+
+	// replays tracks the retained events that are currently sent to new
+	// receivers so unsubscribing can stop and wait for them.
+	replays map[replayKey]*replay
+
+	spool    *Spool
+	flushing atomic.Bool
+
+	clients clientRegistry
+
+	// name is used to label metrics when multiple brokers are in use.
+	name string
 
 	log *slog.Logger
 }
 
-func NewMQTTBroker(ctx context.Context, u string) (*Broker, error) {
-	broker, err := NewBroker(ctx, nil)
+type replayKey struct {
+	typeUrl string
+	msgs    chan *eventsv1.Event
+}
+
+type replay struct {
+	// stop is closed once the receiver is unsubscribed
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Option configures optional broker features.
+type Option func(*Broker)
+
+// WithSpool configures a spool for events that are published while the
+// broker is disconnected from MQTT. Spooled events are published in order
+// once the connection is (re-)established.
+func WithSpool(spool *Spool) Option {
+	return func(b *Broker) {
+		b.spool = spool
+	}
+}
+
+// WithName sets the name of the broker that is used to label metrics.
+// Defaults to "default".
+func WithName(name string) Option {
+	return func(b *Broker) {
+		b.name = name
+	}
+}
+
+func NewMQTTBroker(ctx context.Context, u string, opts ...Option) (*Broker, error) {
+	broker, err := NewBroker(ctx, nil, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create broker: %w", err)
 	}
 
-	opts := mqtt.NewClientOptions()
-	opts.SetAutoReconnect(true)
-	opts.SetOnConnectHandler(broker.HandleOnConnect)
-	opts.AddBroker(u)
+	clientOpts := mqtt.NewClientOptions()
+	clientOpts.SetAutoReconnect(true)
+	clientOpts.SetOnConnectHandler(broker.HandleOnConnect)
+	clientOpts.SetConnectionLostHandler(broker.HandleConnectionLost)
+	clientOpts.AddBroker(u)
 
-	cli := mqtt.NewClient(opts)
+	cli := mqtt.NewClient(clientOpts)
 
 	if token := cli.Connect(); token.Wait() && token.Error() != nil {
 		return nil, token.Error()
@@ -56,15 +119,25 @@ func NewMQTTBroker(ctx context.Context, u string) (*Broker, error) {
 	return broker, nil
 }
 
-func NewBroker(ctx context.Context, cli BlockingMQTTClient) (*Broker, error) {
+func NewBroker(ctx context.Context, cli BlockingMQTTClient, opts ...Option) (*Broker, error) {
 	broker := &Broker{
 		log:          slog.Default().With("subsystem", "broker"),
 		conn:         cli,
 		receivers:    make(map[string][]chan *eventsv1.Event),
 		topics:       make(map[string]struct{}),
-		retainedMsgs: make(map[string]*eventsv1.Event),
+		retainedMsgs: make(map[string]map[string]*eventsv1.Event),
+		retainedKeys: make(map[string]string),
+		replays:      make(map[replayKey]*replay),
+		resolver:     protoregistry.GlobalTypes,
+		name:         "default",
+	}
+
+	for _, opt := range opts {
+		opt(broker)
 	}
 
+	broker.connected.Store(cli != nil)
+
 	return broker, nil
 }
 
@@ -75,12 +148,16 @@ func (b *Broker) HandleOnConnect(cli mqtt.Client) {
 	b.log.Info("successfully connected to MQTT")
 
 	b.conn = &blockingClient{cli}
+	b.connected.Store(true)
+
+	metrics.MQTTConnected.WithLabelValues(b.name).Set(1)
+	metrics.MQTTConnects.WithLabelValues(b.name).Inc()
 
 	// reconnect to all topics
 	b.l.RLock()
 	defer b.l.RUnlock()
 	for t := range b.topics {
-		topic := makeTopic(t)
+		topic := b.subscriptionTopic(t)
 
 		if err := b.conn.Subscribe(topic, 0, b.handleMessage); err != nil {
 			b.log.Error("failed to re-subscribe to topic", "topic", topic, "error", err)
@@ -88,32 +165,93 @@ func (b *Broker) HandleOnConnect(cli mqtt.Client) {
 			b.log.Info("successfully re-subscribed to topic", "topic", topic)
 		}
 	}
+
+	b.startFlush()
+}
+
+// HandleConnectionLost marks the broker as disconnected so events are
+// spooled until HandleOnConnect is called again.
+func (b *Broker) HandleConnectionLost(_ mqtt.Client, err error) {
+	b.connLock.Lock()
+	defer b.connLock.Unlock()
+
+	b.log.Error("lost connection to MQTT", "error", err)
+
+	b.conn = nil
+	b.connected.Store(false)
+
+	metrics.MQTTConnected.WithLabelValues(b.name).Set(0)
+	metrics.MQTTConnectionsLost.WithLabelValues(b.name).Inc()
+}
+
+// Connected reports whether the broker is currently connected to MQTT.
+func (b *Broker) Connected() bool {
+	return b.connected.Load()
 }
 
 func (b *Broker) Subscribe(typeUrl string, msgs chan *eventsv1.Event) {
 	b.l.Lock()
-	defer b.l.Unlock()
 
 	b.receivers[typeUrl] = append(b.receivers[typeUrl], msgs)
 
-	// immediately send any retained message for that typeUrl
-	if msg, ok := b.retainedMsgs[typeUrl]; ok {
-		msgs <- msg
+	retained := make([]*eventsv1.Event, 0, len(b.retainedMsgs[normalizeTypeUrl(typeUrl)]))
+	for _, msg := range b.retainedMsgs[normalizeTypeUrl(typeUrl)] {
+		retained = append(retained, proto.Clone(msg).(*eventsv1.Event))
 	}
 
 	// start to actually subscribe to the topic
 	if _, ok := b.topics[typeUrl]; !ok {
 		go b.subscribe(typeUrl)
 	}
+
+	if len(retained) == 0 {
+		b.l.Unlock()
+		return
+	}
+
+	key := replayKey{typeUrl: typeUrl, msgs: msgs}
+
+	r, ok := b.replays[key]
+	if !ok {
+		r = &replay{stop: make(chan struct{})}
+		b.replays[key] = r
+	}
+	r.wg.Add(1)
+	defer r.wg.Done()
+
+	b.l.Unlock()
+
+	// immediately send any retained message for that typeUrl. This must
+	// not block other subscribers so b.l is not held. Unsubscribe stops
+	// the replay and waits for it so msgs may be closed afterwards.
+	for _, msg := range retained {
+		select {
+		case msgs <- msg:
+		case <-r.stop:
+			return
+		case <-time.After(time.Second * 5):
+			b.log.Warn("failed to dispatch retained event, receiver busy")
+		}
+	}
 }
 
 func (b *Broker) subscribe(typeUrl string) {
-	topic := makeTopic(typeUrl)
+	topic := b.subscriptionTopic(typeUrl)
 
 	b.single.Do(topic, func() (any, error) {
 		b.connLock.Lock()
 		defer b.connLock.Unlock()
 
+		if b.conn == nil {
+			// not yet connected, HandleOnConnect will subscribe to all topics
+			b.l.Lock()
+			defer b.l.Unlock()
+
+			b.topics[typeUrl] = struct{}{}
+
+			return nil, nil
+		}
+
 		if err := b.conn.Subscribe(topic, 0, b.handleMessage); err != nil {
 			b.log.Error("failed to subscribe to topic", "topic", topic)
 		} else {
@@ -129,61 +267,247 @@ func (b *Broker) subscribe(typeUrl string) {
 	})
 }
 
+// Unsubscribe removes msgs as a receiver for typeUrl. Once Unsubscribe
+// returns, no more events are sent to msgs for typeUrl.
+func (b *Broker) Unsubscribe(typeUrl string, msgs chan *eventsv1.Event) {
+	b.l.Lock()
+
+	if b.removeReceiver(typeUrl, msgs) {
+		b.cleanupTopics([]string{typeUrl})
+	}
+
+	replays := b.stopReplays(func(key replayKey) bool {
+		return key.typeUrl == typeUrl && key.msgs == msgs
+	})
+
+	b.l.Unlock()
+
+	waitReplays(replays)
+}
+
+// UnsubscribeAll removes msgs as a receiver for all types. Once
+// UnsubscribeAll returns, no more events are sent to msgs so it is safe
+// to close it.
 func (b *Broker) UnsubscribeAll(msgs chan *eventsv1.Event) {
 	b.l.Lock()
-	defer b.l.Unlock()
 
 	var topicCleanup []string
-	for key, receivers := range b.receivers {
-		for idx, r := range receivers {
-			if r == msgs {
+	for key := range b.receivers {
+		if b.removeReceiver(key, msgs) {
+			topicCleanup = append(topicCleanup, key)
+		}
+	}
 
-				b.receivers[key] = append(receivers[:idx], receivers[idx+1:]...)
+	b.cleanupTopics(topicCleanup)
 
-				b.log.Debug("removing subscriber from topic", "topic", key, "receiverCount", len(b.receivers[key]))
-			}
+	replays := b.stopReplays(func(key replayKey) bool {
+		return key.msgs == msgs
+	})
+
+	b.l.Unlock()
+
+	waitReplays(replays)
+}
+
+// stopReplays stops all retained event replays matching fn and returns
+// them. b.l must be held.
+func (b *Broker) stopReplays(fn func(replayKey) bool) []*replay {
+	var result []*replay
+
+	for key, r := range b.replays {
+		if fn(key) {
+			delete(b.replays, key)
+			close(r.stop)
+
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
+
+func waitReplays(replays []*replay) {
+	for _, r := range replays {
+		r.wg.Wait()
+	}
+}
+
+// removeReceiver removes msgs from the receivers of typeUrl and reports
+// whether there are no receivers left. b.l must be held.
+func (b *Broker) removeReceiver(typeUrl string, msgs chan *eventsv1.Event) bool {
+	receivers, ok := b.receivers[typeUrl]
+	if !ok {
+		return false
+	}
+
+	for idx, r := range receivers {
+		if r == msgs {
+			receivers = append(receivers[:idx], receivers[idx+1:]...)
+
+			b.log.Debug("removing subscriber from topic", "topic", typeUrl, "receiverCount", len(receivers))
+
+			break
+		}
+	}
+
+	if len(receivers) > 0 {
+		b.receivers[typeUrl] = receivers
+		return false
+	}
+
+	b.log.Info("marking topic for cleanup", "topic", typeUrl)
+	delete(b.receivers, typeUrl)
+
+	return true
+}
+
+// cleanupTopics unsubscribes from all MQTT topics in topicCleanup.
+// b.l must be held.
+func (b *Broker) cleanupTopics(topicCleanup []string) {
+	if len(topicCleanup) == 0 {
+		return
+	}
+
+	for _, t := range topicCleanup {
+		delete(b.topics, t)
+		delete(b.retainedMsgs, normalizeTypeUrl(t))
+	}
+
+	// the wildcard subscription caches retained events of all types, only
+	// keep those of types that are still subscribed.
+	if slices.Contains(topicCleanup, Wildcard) {
+		subscribed := make(map[string]bool, len(b.topics))
+		for t := range b.topics {
+			subscribed[normalizeTypeUrl(t)] = true
+		}
 
-			if len(b.receivers[key]) == 0 {
-				b.log.Info("marking topic for cleanup", "topic", key)
-				topicCleanup = append(topicCleanup, key)
+		for t := range b.retainedMsgs {
+			if !subscribed[t] {
+				delete(b.retainedMsgs, t)
 			}
 		}
 	}
 
-	if len(topicCleanup) > 0 {
-		for _, t := range topicCleanup {
-			delete(b.topics, t)
-			delete(b.retainedMsgs, t)
+	go func() {
+		b.connLock.Lock()
+		defer b.connLock.Unlock()
+
+		if b.conn == nil {
+			// not connected, HandleOnConnect only subscribes to b.topics
+			return
+		}
+
+		for idx, t := range topicCleanup {
+			topicCleanup[idx] = b.subscriptionTopic(t)
+		}
+
+		if err := b.conn.Unsubscribe(topicCleanup...); err != nil {
+			b.log.Error("failed to unsubscribe from unused topics", "error", err)
+		}
+
+		b.log.Debug("successfully unsubscribed from unused topics", "topics", topicCleanup)
+	}()
+}
+
+func (b *Broker) Publish(evt *eventsv1.Event) error {
+	b.connLock.Lock()
+	defer b.connLock.Unlock()
+
+	if b.spool == nil {
+		if b.conn == nil {
+			return errors.New("not yet connected, please try again later")
+		}
+
+		return b.publish(evt)
+	}
+
+	// publish events in order, if there are spooled events evt is
+	// published once the spool has been flushed.
+	if b.conn != nil && b.spool.Len() == 0 {
+		err := b.publish(evt)
+		if err == nil {
+			return nil
 		}
 
-		go func() {
-			b.connLock.Lock()
-			defer b.connLock.Unlock()
+		b.log.Warn("failed to publish message, spooling", "error", err)
+	}
 
-			for idx, t := range topicCleanup {
-				topicCleanup[idx] = makeTopic(t)
+	if err := b.spool.Push(evt); err != nil {
+		return fmt.Errorf("failed to spool message: %w", err)
+	}
+
+	b.log.Info("spooled message", "typeUrl", evt.Event.TypeUrl, "depth", b.spool.Len())
+
+	if b.conn != nil {
+		b.startFlush()
+	}
+
+	return nil
+}
+
+// startFlush starts to publish all spooled events in the background unless
+// a flush is already running. Events are published in batches of
+// spoolBatchSize so Publish and HandleOnConnect are not blocked until the
+// whole spool has been flushed.
+func (b *Broker) startFlush() {
+	if b.spool == nil || !b.flushing.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		for {
+			more, err := b.flushBatch()
+			if err != nil {
+				b.log.Error("failed to flush publish spool", "error", err, "depth", b.spool.Len())
 			}
 
-			if err := b.conn.Unsubscribe(topicCleanup...); err != nil {
-				b.log.Error("failed to unsubscribe from unused topics", "error", err)
+			if more && err == nil {
+				continue
 			}
 
-			b.log.Debug("successfully unsubscribed from unused topics", "topics", topicCleanup)
-		}()
-	}
+			b.flushing.Store(false)
+
+			// Publish may have spooled an event after the last batch but
+			// before the flag has been cleared.
+			if err != nil || !b.pendingFlush() || !b.flushing.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	}()
 }
 
-func (b *Broker) Publish(evt *eventsv1.Event) error {
-	blob, err := proto.Marshal(evt)
-	if err != nil {
-		return fmt.Errorf("failed to marshal protobuf: %w", err)
+// flushBatch publishes up to spoolBatchSize spooled events and reports
+// whether more events are waiting.
+func (b *Broker) flushBatch() (bool, error) {
+	b.connLock.Lock()
+	defer b.connLock.Unlock()
+
+	if b.conn == nil || b.spool.Len() == 0 {
+		return false, nil
 	}
 
+	n, err := b.spool.Flush(spoolBatchSize, b.publish)
+
+	b.log.Info("flushed publish spool", "count", n, "depth", b.spool.Len())
+
+	return b.spool.Len() > 0, err
+}
+
+// pendingFlush reports whether there are spooled events that can be
+// published.
+func (b *Broker) pendingFlush() bool {
 	b.connLock.Lock()
 	defer b.connLock.Unlock()
 
-	if b.conn == nil {
-		return errors.New("not yet connected, please try again later")
+	return b.conn != nil && b.spool.Len() > 0
+}
+
+// publish publishes evt to MQTT. b.connLock must be held and b.conn must not
+// be nil.
+func (b *Broker) publish(evt *eventsv1.Event) error {
+	blob, err := proto.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal protobuf: %w", err)
 	}
 
 	topic := makeTopic(evt.Event.TypeUrl)
@@ -192,12 +516,40 @@ func (b *Broker) Publish(evt *eventsv1.Event) error {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
+	// keep a retained copy per entity for keyed types. The event has
+	// already been published so a failure is only logged.
+	if evt.Retained {
+		if keyTopic, ok := b.retainedTopic(evt); ok {
+			if err := b.conn.Publish(keyTopic, 0, true, blob); err != nil {
+				b.log.Error("failed to publish retained state", "topic", keyTopic, "error", err)
+			}
+		}
+	}
+
+	metrics.EventsPublished.WithLabelValues(b.name, b.typeLabel(evt.Event.TypeUrl)).Inc()
+
 	b.log.Info("published new message", "topic", topic)
 
 	return nil
 }
 
 func (b *Broker) handleMessage(_ mqtt.Client, msg mqtt.Message) {
+	// an empty payload clears the retained message of a topic
+	if len(msg.Payload()) == 0 {
+		typeUrl, key := parseTopic(msg.Topic())
+
+		b.l.Lock()
+		delete(b.retainedMsgs[typeUrl], key)
+		if len(b.retainedMsgs[typeUrl]) == 0 {
+			delete(b.retainedMsgs, typeUrl)
+		}
+		b.l.Unlock()
+
+		b.log.Debug("retained message cleared", "typeUrl", typeUrl, "key", key)
+
+		return
+	}
+
 	var pb = new(eventsv1.Event)
 
 	if err := proto.Unmarshal(msg.Payload(), pb); err != nil {
@@ -208,26 +560,109 @@ func (b *Broker) handleMessage(_ mqtt.Client, msg mqtt.Message) {
 	typeUrl := strings.TrimPrefix(pb.Event.TypeUrl, "type.googleapis.com/")
 	b.log.Debug("received new event from mqtt", "typeUrl", typeUrl, "topic", msg.Topic())
 
-	b.l.RLock()
-	defer b.l.RUnlock()
+	_, key := parseTopic(msg.Topic())
+	_, keyed := b.keyPath(typeUrl)
+
+	// Events of keyed types are published to the base topic and, if
+	// retained, to a sub-topic per entity. Subscribers receive live events
+	// from the base topic and retained state from the entity topics.
+	if keyed {
+		switch {
+		case key == "" && msg.Retained():
+			// the retained message of the base topic is one of the
+			// entity states and would be delivered twice
+			return
+
+		case key != "" && !msg.Retained():
+			// the live copy of an event that has also been published to
+			// the base topic
+			pb.Retained = true
+			b.setRetained(typeUrl, key, pb)
+
+			return
+
+		case key == "" && pb.Retained:
+			// retained events published to the base topic directly
+			if path, _ := b.keyPath(typeUrl); path != "" {
+				key, _ = b.extractKey(pb, path)
+			}
+		}
+	}
+
+	metrics.EventsReceived.WithLabelValues(b.name, b.typeLabel(typeUrl)).Inc()
 
-	if msg.Retained() {
+	// continue the trace of the publisher, local subscribers receive the
+	// context of the consumer span.
+	_, span := tracing.StartReceive(context.Background(), msg.Topic(), pb)
+	span.SetAttributes(attribute.String("events.broker", b.name))
+	defer span.End()
+
+	if msg.Retained() || pb.Retained {
 		pb.Retained = true
 
-		b.retainedMsgs[typeUrl] = pb
+		b.setRetained(typeUrl, key, pb)
+	}
+
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	receivers := b.receivers[typeUrl]
+	if typeUrl != Wildcard {
+		receivers = append(receivers[:len(receivers):len(receivers)], b.receivers[Wildcard]...)
 	}
 
-	for _, m := range b.receivers[typeUrl] {
+	var (
+		fill      = metrics.QueueFill.WithLabelValues(b.name)
+		delivered = metrics.EventsDelivered.WithLabelValues(b.name, b.typeLabel(typeUrl))
+		dropped   = metrics.EventsDropped.WithLabelValues(b.name, b.typeLabel(typeUrl))
+	)
+
+	for _, m := range receivers {
+		if cap(m) > 0 {
+			fill.Observe(float64(len(m)) / float64(cap(m)))
+		}
+
 		select {
 		case m <- proto.Clone(pb).(*eventsv1.Event):
+			delivered.Inc()
 		case <-time.After(time.Second * 5):
-			b.log.Warn("failed to dispatch event, receiver busy")
+			dropped.Inc()
+			b.log.Warn("failed to dispatch event, receiver busy", "typeUrl", typeUrl)
 		}
 	}
 }
 
+// typeLabel returns the value of the type label for typeUrl. Only types
+// compiled into the service or configured with a retained key are reported
+// by name, all other types are reported as metrics.OtherType.
+func (b *Broker) typeLabel(typeUrl string) string {
+	typeUrl = normalizeTypeUrl(typeUrl)
+
+	if _, ok := b.retainedKeys[typeUrl]; ok {
+		return typeUrl
+	}
+
+	if _, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(typeUrl)); err == nil {
+		return typeUrl
+	}
+
+	return metrics.OtherType
+}
+
+// setRetained caches pb as the retained event of typeUrl and key.
+func (b *Broker) setRetained(typeUrl, key string, pb *eventsv1.Event) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if b.retainedMsgs[typeUrl] == nil {
+		b.retainedMsgs[typeUrl] = make(map[string]*eventsv1.Event)
+	}
+
+	b.retainedMsgs[typeUrl][key] = pb
+}
+
 func makeTopic(typeUrl string) string {
 	typeUrl = strings.TrimPrefix(typeUrl, "type.googleapis.com/")
 
-	return fmt.Sprintf("cis/protobuf/events/%s", typeUrl)
+	return topicPrefix + typeUrl
 }
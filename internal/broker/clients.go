@@ -0,0 +1,175 @@
+package broker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+)
+
+// ErrDisconnected is the cause of a subscription that has been
+// disconnected using Broker.Disconnect.
+var ErrDisconnected = errors.New("disconnected by administrator")
+
+// ErrUnknownClient is returned by Broker.Disconnect if there's no client
+// with the given ID.
+var ErrUnknownClient = errors.New("unknown client")
+
+// ClientInfo describes a subscribing client.
+type ClientInfo struct {
+	ID          string
+	Kind        string
+	Peer        string
+	User        string
+	ConnectTime time.Time
+
+	// The fields below are populated by Broker.Clients.
+	TypeURLs      []string
+	QueueDepth    int
+	QueueCapacity int
+}
+
+type client struct {
+	info       ClientInfo
+	msgs       chan *eventsv1.Event
+	disconnect func()
+}
+
+// TopicInfo describes a topic the broker is subscribed to.
+type TopicInfo struct {
+	TypeURL     string
+	MQTTTopic   string
+	Subscribers int
+	Retained    int
+}
+
+type clientRegistry struct {
+	lock    sync.Mutex
+	clients map[string]*client
+}
+
+// RegisterClient registers msgs as the subscription channel of a client so
+// it shows up in Clients. disconnect is called by Disconnect and must cause
+// the client to unsubscribe. The returned function must be called once the
+// client is gone.
+//
+// msgs may be nil for clients that are not subscribed at the broker
+// directly. info.TypeURLs is reported as is for those clients.
+func (b *Broker) RegisterClient(msgs chan *eventsv1.Event, info ClientInfo, disconnect func()) (string, func()) {
+	info.ID = newClientID()
+	if info.ConnectTime.IsZero() {
+		info.ConnectTime = time.Now()
+	}
+
+	b.clients.lock.Lock()
+	defer b.clients.lock.Unlock()
+
+	if b.clients.clients == nil {
+		b.clients.clients = make(map[string]*client)
+	}
+
+	b.clients.clients[info.ID] = &client{
+		info:       info,
+		msgs:       msgs,
+		disconnect: disconnect,
+	}
+
+	return info.ID, func() {
+		b.clients.lock.Lock()
+		defer b.clients.lock.Unlock()
+
+		delete(b.clients.clients, info.ID)
+	}
+}
+
+// Clients returns all registered clients ordered by their connect time.
+func (b *Broker) Clients() []ClientInfo {
+	b.clients.lock.Lock()
+	clients := make([]*client, 0, len(b.clients.clients))
+	for _, c := range b.clients.clients {
+		clients = append(clients, c)
+	}
+	b.clients.lock.Unlock()
+
+	b.l.RLock()
+	types := make(map[chan *eventsv1.Event][]string)
+	for typeUrl, receivers := range b.receivers {
+		for _, r := range receivers {
+			types[r] = append(types[r], typeUrl)
+		}
+	}
+	b.l.RUnlock()
+
+	result := make([]ClientInfo, len(clients))
+	for idx, c := range clients {
+		info := c.info
+
+		if c.msgs != nil {
+			info.TypeURLs = types[c.msgs]
+			info.QueueDepth = len(c.msgs)
+			info.QueueCapacity = cap(c.msgs)
+		}
+
+		info.TypeURLs = slices.Clone(info.TypeURLs)
+		sort.Strings(info.TypeURLs)
+
+		result[idx] = info
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ConnectTime.Before(result[j].ConnectTime)
+	})
+
+	return result
+}
+
+// Disconnect forcibly disconnects the client with the given id.
+func (b *Broker) Disconnect(id string) error {
+	b.clients.lock.Lock()
+	c, ok := b.clients.clients[id]
+	b.clients.lock.Unlock()
+
+	if !ok {
+		return ErrUnknownClient
+	}
+
+	b.log.Info("disconnecting client", "id", id, "peer", c.info.Peer, "user", c.info.User)
+
+	c.disconnect()
+
+	return nil
+}
+
+// Topics returns all topics the broker is subscribed to.
+func (b *Broker) Topics() []TopicInfo {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	result := make([]TopicInfo, 0, len(b.topics))
+	for typeUrl := range b.topics {
+		result = append(result, TopicInfo{
+			TypeURL:     typeUrl,
+			MQTTTopic:   b.subscriptionTopic(typeUrl),
+			Subscribers: len(b.receivers[typeUrl]),
+			Retained:    len(b.retainedMsgs[normalizeTypeUrl(typeUrl)]),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TypeURL < result[j].TypeURL
+	})
+
+	return result
+}
+
+func newClientID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
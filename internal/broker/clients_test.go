@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+)
+
+func TestClients(t *testing.T) {
+	b, err := NewBroker(context.Background(), &fakeClient{})
+	require.NoError(t, err)
+
+	typeUrl := "tkd.events.v1.SubscribeRequest"
+
+	msgs := make(chan *eventsv1.Event, 10)
+	b.Subscribe(typeUrl, msgs)
+
+	disconnected := false
+	id, unregister := b.RegisterClient(msgs, ClientInfo{
+		Kind: "subscribe",
+		Peer: "127.0.0.1:1234",
+		User: "alice",
+	}, func() {
+		disconnected = true
+	})
+
+	clients := b.Clients()
+	require.Len(t, clients, 1)
+	require.Equal(t, id, clients[0].ID)
+	require.Equal(t, "subscribe", clients[0].Kind)
+	require.Equal(t, "alice", clients[0].User)
+	require.Equal(t, []string{typeUrl}, clients[0].TypeURLs)
+	require.Equal(t, 10, clients[0].QueueCapacity)
+	require.False(t, clients[0].ConnectTime.IsZero())
+
+	// subscribing to the MQTT topic happens in the background
+	require.Eventually(t, func() bool {
+		return len(b.Topics()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	topics := b.Topics()
+	require.Equal(t, typeUrl, topics[0].TypeURL)
+	require.Equal(t, makeTopic(typeUrl), topics[0].MQTTTopic)
+	require.Equal(t, 1, topics[0].Subscribers)
+
+	require.ErrorIs(t, b.Disconnect("unknown"), ErrUnknownClient)
+
+	require.NoError(t, b.Disconnect(id))
+	require.True(t, disconnected)
+
+	unregister()
+	require.Empty(t, b.Clients())
+}
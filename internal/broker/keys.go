@@ -0,0 +1,178 @@
+package broker
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const topicPrefix = "cis/protobuf/events/"
+
+// TypeResolver is used to decode event payloads when extracting retained
+// state keys.
+type TypeResolver interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+}
+
+// WithRetainedKeys configures event types whose retained state is kept per
+// entity. keys maps a type URL to the path of a payload field (e.g. "room_id"
+// or "room.id") that identifies the entity. Events of keyed types are
+// published to the type's topic as usual. Retained events are additionally
+// published to a sub-topic per key so MQTT retains the latest event for each
+// entity. resolver is used to decode event payloads and may be nil to use the
+// global registry.
+func WithRetainedKeys(keys map[string]string, resolver TypeResolver) Option {
+	return func(b *Broker) {
+		for typeUrl, path := range keys {
+			b.retainedKeys[normalizeTypeUrl(typeUrl)] = path
+		}
+
+		if resolver != nil {
+			b.resolver = resolver
+		}
+	}
+}
+
+// keyPath returns the configured key field path for typeUrl.
+func (b *Broker) keyPath(typeUrl string) (string, bool) {
+	path, ok := b.retainedKeys[normalizeTypeUrl(typeUrl)]
+
+	return path, ok
+}
+
+// retainedTopic returns the MQTT topic that holds the retained state of the
+// entity of evt. It returns false if evt is not of a keyed type or the key
+// cannot be extracted.
+func (b *Broker) retainedTopic(evt *eventsv1.Event) (string, bool) {
+	path, ok := b.keyPath(evt.Event.TypeUrl)
+	if !ok {
+		return "", false
+	}
+
+	key, err := b.extractKey(evt, path)
+	if err != nil {
+		b.log.Warn("failed to extract retained state key", "typeUrl", evt.Event.TypeUrl, "path", path, "error", err)
+
+		return "", false
+	}
+
+	return makeTopic(evt.Event.TypeUrl) + "/" + escapeKey(key), true
+}
+
+// subscriptionTopic returns the MQTT topic filter for typeUrl. Keyed types
+// subscribe to all sub-topics as well, the filter also matches the topic of
+// the type itself.
+func (b *Broker) subscriptionTopic(typeUrl string) string {
+	if _, ok := b.keyPath(typeUrl); ok {
+		return makeTopic(typeUrl) + "/#"
+	}
+
+	return makeTopic(typeUrl)
+}
+
+func (b *Broker) extractKey(evt *eventsv1.Event, path string) (string, error) {
+	msg, err := anypb.UnmarshalNew(evt.Event, proto.UnmarshalOptions{Resolver: b.resolver})
+	if err != nil {
+		return "", err
+	}
+
+	return fieldValue(msg.ProtoReflect(), path)
+}
+
+// fieldValue returns the string representation of the field at path. Path
+// segments are separated by dots and may use the proto or JSON field name.
+func fieldValue(msg protoreflect.Message, path string) (string, error) {
+	segments := strings.Split(path, ".")
+
+	for idx, name := range segments {
+		fields := msg.Descriptor().Fields()
+
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = fields.ByJSONName(name)
+		}
+
+		if fd == nil || fd.IsList() || fd.IsMap() {
+			return "", fmt.Errorf("invalid field %q in %s", name, msg.Descriptor().FullName())
+		}
+
+		if idx < len(segments)-1 {
+			if fd.Kind() != protoreflect.MessageKind {
+				return "", fmt.Errorf("field %q in %s is not a message", name, msg.Descriptor().FullName())
+			}
+
+			msg = msg.Get(fd).Message()
+
+			continue
+		}
+
+		value := msg.Get(fd)
+
+		switch fd.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind, protoreflect.BytesKind:
+			return "", fmt.Errorf("field %q in %s cannot be used as a key", name, msg.Descriptor().FullName())
+
+		case protoreflect.EnumKind:
+			if ev := fd.Enum().Values().ByNumber(value.Enum()); ev != nil {
+				return string(ev.Name()), nil
+			}
+		}
+
+		key := value.String()
+		if key == "" {
+			return "", fmt.Errorf("field %q in %s is empty", name, msg.Descriptor().FullName())
+		}
+
+		return key, nil
+	}
+
+	return "", fmt.Errorf("empty key path")
+}
+
+// parseTopic returns the type URL and the entity key, if any, of topic.
+func parseTopic(topic string) (string, string) {
+	typeUrl, key, _ := strings.Cut(strings.TrimPrefix(topic, topicPrefix), "/")
+
+	if unescaped, err := url.PathUnescape(key); err == nil {
+		key = unescaped
+	}
+
+	return typeUrl, key
+}
+
+// escapeKey escapes key for use as a single MQTT topic level.
+func escapeKey(key string) string {
+	return strings.ReplaceAll(url.PathEscape(key), "+", "%2B")
+}
+
+func normalizeTypeUrl(typeUrl string) string {
+	return strings.TrimPrefix(typeUrl, "type.googleapis.com/")
+}
+
+// RetainedKey returns the key of the entity whose retained state is held by
+// evt. It returns an empty string if evt is not of a keyed type or the key
+// cannot be extracted, all retained events of such types share one state.
+func (b *Broker) RetainedKey(evt *eventsv1.Event) string {
+	if evt.GetEvent() == nil {
+		return ""
+	}
+
+	path, ok := b.keyPath(evt.Event.TypeUrl)
+	if !ok {
+		return ""
+	}
+
+	key, err := b.extractKey(evt, path)
+	if err != nil {
+		return ""
+	}
+
+	return key
+}
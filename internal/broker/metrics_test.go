@@ -0,0 +1,46 @@
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/metrics"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMetrics(t *testing.T) {
+	cli := &fakeClient{}
+
+	b, err := NewBroker(context.Background(), cli, WithName("metrics-test"))
+	require.NoError(t, err)
+
+	typeUrl := "tkd.events.v1.SubscribeRequest"
+
+	evt := makeEvent(t, "1")
+	require.NoError(t, b.Publish(evt))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsPublished.WithLabelValues("metrics-test", typeUrl)))
+
+	msgs := make(chan *eventsv1.Event, 1)
+	b.Subscribe(typeUrl, msgs)
+
+	payload, err := proto.Marshal(evt)
+	require.NoError(t, err)
+
+	b.handleMessage(nil, &retainedMessage{topic: makeTopic(typeUrl), payload: payload})
+
+	<-msgs
+
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsReceived.WithLabelValues("metrics-test", typeUrl)))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsDelivered.WithLabelValues("metrics-test", typeUrl)))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.EventsDropped.WithLabelValues("metrics-test", typeUrl)))
+
+	// unknown types are not used as label values
+	unknown := makeEvent(t, "2")
+	unknown.Event.TypeUrl = "type.googleapis.com/example.Unknown"
+
+	require.NoError(t, b.Publish(unknown))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsPublished.WithLabelValues("metrics-test", metrics.OtherType)))
+}
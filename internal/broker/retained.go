@@ -0,0 +1,157 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+)
+
+// RetainedWait is the maximum time to wait for MQTT to deliver retained
+// messages after subscribing to a topic.
+var RetainedWait = time.Second
+
+// RetainedEvent is a retained event together with it's entity key. Key is
+// empty for event types without a configured key field.
+type RetainedEvent struct {
+	TypeURL string
+	Key     string
+	Event   *eventsv1.Event
+}
+
+// Retained returns the current retained events for typeUrl. For types with
+// a configured key field, one event per entity is returned. If the broker is
+// not yet subscribed to typeUrl, it subscribes temporarily and waits up to
+// RetainedWait for MQTT to deliver the retained messages.
+func (b *Broker) Retained(ctx context.Context, typeUrl string) ([]RetainedEvent, error) {
+	typeUrl = normalizeTypeUrl(typeUrl)
+
+	b.l.RLock()
+	_, subscribed := b.topics[typeUrl]
+	b.l.RUnlock()
+
+	if subscribed {
+		return b.retainedSnapshot(typeUrl), nil
+	}
+
+	// keyed types may have multiple retained messages so we need to wait
+	// for all of them.
+	_, keyed := b.keyPath(typeUrl)
+
+	return b.collectRetained(ctx, typeUrl, !keyed)
+}
+
+// ListRetained returns the retained events of all types by temporarily
+// subscribing to all topics. To avoid duplicate deliveries to subscribers of
+// overlapping topics, ListRetained should only be used on a dedicated broker
+// connection without other subscribers. Note that MQTT does not re-deliver
+// retained messages if the broker already holds a wildcard subscription. In
+// this case, only retained messages of subscribed types are returned.
+func (b *Broker) ListRetained(ctx context.Context) ([]RetainedEvent, error) {
+	return b.collectRetained(ctx, Wildcard, false)
+}
+
+// retainedSnapshot returns all cached retained events of typeUrl, or of all
+// types if typeUrl is empty, ordered by type URL and key.
+func (b *Broker) retainedSnapshot(typeUrl string) []RetainedEvent {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	var result []RetainedEvent
+
+	for t, byKey := range b.retainedMsgs {
+		if typeUrl != "" && t != typeUrl {
+			continue
+		}
+
+		for key, evt := range byKey {
+			result = append(result, RetainedEvent{
+				TypeURL: t,
+				Key:     key,
+				Event:   evt,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TypeURL != result[j].TypeURL {
+			return result[i].TypeURL < result[j].TypeURL
+		}
+
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// collectRetained temporarily subscribes to typeUrl so MQTT delivers
+// retained messages and waits until RetainedWait elapsed or ctx is
+// cancelled. If first is true, collectRetained returns after the first
+// retained event. The snapshot is taken before unsubscribing since the
+// retained cache of unused topics is cleared.
+func (b *Broker) collectRetained(ctx context.Context, typeUrl string, first bool) ([]RetainedEvent, error) {
+	snapshotType := typeUrl
+	if typeUrl == Wildcard {
+		snapshotType = ""
+	}
+
+	msgs := make(chan *eventsv1.Event, 100)
+
+	b.Subscribe(typeUrl, msgs)
+	defer b.Unsubscribe(typeUrl, msgs)
+
+	timer := time.NewTimer(RetainedWait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-timer.C:
+			return b.retainedSnapshot(snapshotType), nil
+
+		case msg := <-msgs:
+			if msg.Retained && first {
+				return b.retainedSnapshot(snapshotType), nil
+			}
+		}
+	}
+}
+
+// ClearRetained removes the retained message for typeUrl and key by
+// publishing an empty retained payload. key must be empty for types without
+// a configured key field.
+func (b *Broker) ClearRetained(typeUrl string, key string) error {
+	typeUrl = normalizeTypeUrl(typeUrl)
+
+	topic := makeTopic(typeUrl)
+	if key != "" {
+		topic += "/" + escapeKey(key)
+	}
+
+	b.connLock.Lock()
+	defer b.connLock.Unlock()
+
+	if b.conn == nil {
+		return errors.New("not yet connected, please try again later")
+	}
+
+	if err := b.conn.Publish(topic, 0, true, nil); err != nil {
+		return fmt.Errorf("failed to clear retained message: %w", err)
+	}
+
+	b.l.Lock()
+	delete(b.retainedMsgs[typeUrl], key)
+	if len(b.retainedMsgs[typeUrl]) == 0 {
+		delete(b.retainedMsgs, typeUrl)
+	}
+	b.l.Unlock()
+
+	b.log.Info("cleared retained message", "typeUrl", typeUrl, "key", key)
+
+	return nil
+}
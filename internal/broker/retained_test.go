@@ -0,0 +1,251 @@
+package broker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+type retainedMessage struct {
+	mqtt.Message
+
+	topic   string
+	payload []byte
+}
+
+func (m *retainedMessage) Topic() string   { return m.topic }
+func (m *retainedMessage) Payload() []byte { return m.payload }
+func (m *retainedMessage) Retained() bool  { return true }
+
+type liveMessage struct {
+	mqtt.Message
+
+	topic   string
+	payload []byte
+}
+
+func (m *liveMessage) Topic() string   { return m.topic }
+func (m *liveMessage) Payload() []byte { return m.payload }
+func (m *liveMessage) Retained() bool  { return false }
+
+// retainedClient is a fake MQTT client that delivers retained messages on
+// subscription.
+type retainedClient struct {
+	l        sync.Mutex
+	retained map[string][]byte
+}
+
+func (c *retainedClient) Subscribe(topic string, _ byte, handler mqtt.MessageHandler) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	for t, payload := range c.retained {
+		// like MQTT, a multi-level wildcard also matches the parent topic
+		parent := strings.TrimSuffix(topic, "/#")
+
+		if t == topic || (strings.HasSuffix(topic, "/#") && (t == parent || strings.HasPrefix(t, parent+"/"))) {
+			go handler(nil, &retainedMessage{topic: t, payload: payload})
+		}
+	}
+
+	return nil
+}
+
+func (c *retainedClient) Unsubscribe(...string) error { return nil }
+
+func (c *retainedClient) Publish(topic string, _ byte, retained bool, payload []byte) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if retained {
+		if len(payload) == 0 {
+			delete(c.retained, topic)
+		} else {
+			c.retained[topic] = payload
+		}
+	}
+
+	return nil
+}
+
+func TestRetained(t *testing.T) {
+	defer func(wait time.Duration) {
+		RetainedWait = wait
+	}(RetainedWait)
+
+	RetainedWait = 100 * time.Millisecond
+
+	cli := &retainedClient{retained: make(map[string][]byte)}
+
+	b, err := NewBroker(context.Background(), cli)
+	require.NoError(t, err)
+
+	evt := makeEvent(t, "on-duty")
+	evt.Retained = true
+	require.NoError(t, b.Publish(evt))
+
+	// the broker is not subscribed so the retained message is fetched from
+	// MQTT
+	events, err := b.Retained(context.Background(), "type.googleapis.com/tkd.events.v1.SubscribeRequest")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Empty(t, events[0].Key)
+	require.True(t, proto.Equal(evt.Event, events[0].Event.Event))
+
+	all, err := b.ListRetained(context.Background())
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, "tkd.events.v1.SubscribeRequest", all[0].TypeURL)
+
+	// retained events of unsubscribed types are not kept once the wildcard
+	// subscription has been removed
+	require.Empty(t, b.retainedSnapshot(""))
+
+	// clear the retained message
+	require.NoError(t, b.ClearRetained("tkd.events.v1.SubscribeRequest", ""))
+
+	events, err = b.Retained(context.Background(), "tkd.events.v1.SubscribeRequest")
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestKeyedRetained(t *testing.T) {
+	defer func(wait time.Duration) {
+		RetainedWait = wait
+	}(RetainedWait)
+
+	RetainedWait = 100 * time.Millisecond
+
+	cli := &retainedClient{retained: make(map[string][]byte)}
+
+	b, err := NewBroker(context.Background(), cli, WithRetainedKeys(map[string]string{
+		"tkd.events.v1.SubscribeRequest": "subscribe",
+	}, nil))
+	require.NoError(t, err)
+
+	for _, room := range []string{"room/1", "room/2", "room/1"} {
+		evt := makeEvent(t, room)
+		evt.Retained = true
+		require.NoError(t, b.Publish(evt))
+	}
+
+	// events are still published to the type's topic and the state of each
+	// entity is retained in it's own topic
+	require.Len(t, cli.retained, 3)
+	require.Contains(t, cli.retained, "cis/protobuf/events/tkd.events.v1.SubscribeRequest")
+	require.Contains(t, cli.retained, "cis/protobuf/events/tkd.events.v1.SubscribeRequest/room%2F1")
+
+	events, err := b.Retained(context.Background(), "tkd.events.v1.SubscribeRequest")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "room/1", events[0].Key)
+	require.Equal(t, "room/2", events[1].Key)
+	require.Equal(t, "room/2", b.RetainedKey(events[1].Event))
+
+	// new subscribers receive the state of all entities
+	msgs := make(chan *eventsv1.Event, 10)
+	b.Subscribe("tkd.events.v1.SubscribeRequest", msgs)
+	defer b.UnsubscribeAll(msgs)
+
+	var keys []string
+	for len(keys) < 2 {
+		select {
+		case msg := <-msgs:
+			var req eventsv1.SubscribeRequest
+			require.NoError(t, msg.Event.UnmarshalTo(&req))
+
+			keys = append(keys, req.GetSubscribe())
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for retained events")
+		}
+	}
+
+	require.ElementsMatch(t, []string{"room/1", "room/2"}, keys)
+
+	// the retained message of the type's topic is not delivered twice
+	select {
+	case <-msgs:
+		t.Fatal("received duplicate retained event")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// live copies published to an entity topic only update the retained
+	// state, subscribers receive the event from the type's topic.
+	update := makeEvent(t, "room/3")
+	update.Retained = true
+
+	payload, err := proto.Marshal(update)
+	require.NoError(t, err)
+
+	b.handleMessage(nil, &liveMessage{topic: "cis/protobuf/events/tkd.events.v1.SubscribeRequest/room%2F3", payload: payload})
+
+	select {
+	case <-msgs:
+		t.Fatal("received live copy of entity topic")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.Len(t, b.retainedSnapshot("tkd.events.v1.SubscribeRequest"), 3)
+
+	// clear the state of a single entity
+	require.NoError(t, b.ClearRetained("tkd.events.v1.SubscribeRequest", "room/1"))
+	require.Len(t, cli.retained, 2)
+}
+
+func TestFieldValue(t *testing.T) {
+	evt := makeEvent(t, "")
+
+	b, err := NewBroker(context.Background(), nil)
+	require.NoError(t, err)
+
+	_, err = b.extractKey(evt, "subscribe")
+	require.Error(t, err, "empty keys are rejected")
+
+	_, err = b.extractKey(evt, "does_not_exist")
+	require.Error(t, err)
+}
+
+func TestUnsubscribeDuringRetainedReplay(t *testing.T) {
+	b, err := NewBroker(context.Background(), &fakeClient{})
+	require.NoError(t, err)
+
+	typeUrl := "tkd.events.v1.SubscribeRequest"
+
+	// keep the type subscribed so the retained cache is not cleared
+	keep := make(chan *eventsv1.Event, 100)
+	b.Subscribe(typeUrl, keep)
+	defer b.UnsubscribeAll(keep)
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		b.setRetained(typeUrl, key, makeEvent(t, key))
+	}
+
+	for i := 0; i < 100; i++ {
+		msgs := make(chan *eventsv1.Event)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			b.Subscribe(typeUrl, msgs)
+		}()
+
+		// the replay of the remaining retained events is still in flight
+		<-msgs
+
+		b.UnsubscribeAll(msgs)
+		close(msgs)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("retained replay has not been stopped")
+		}
+	}
+}
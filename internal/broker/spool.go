@@ -0,0 +1,157 @@
+package broker
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrSpoolFull is returned by Spool.Push if the maximum number of spooled
+// events has been reached.
+var ErrSpoolFull = errors.New("publish spool is full")
+
+var spoolBucket = []byte("spool")
+
+// Spool is a disk-backed FIFO queue for events that are published while the
+// broker is disconnected from MQTT.
+type Spool struct {
+	db      *bolt.DB
+	maxSize int
+
+	size    atomic.Int64
+	dropped atomic.Int64
+}
+
+// OpenSpool opens or creates the spool database at path. maxSize limits the
+// number of spooled events, a value <= 0 disables the limit.
+func OpenSpool(path string, maxSize int) (*Spool, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool database: %w", err)
+	}
+
+	s := &Spool{
+		db:      db,
+		maxSize: maxSize,
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(spoolBucket)
+		if err != nil {
+			return err
+		}
+
+		s.size.Store(int64(bucket.Stats().KeyN))
+
+		return nil
+	}); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *Spool) Close() error {
+	return s.db.Close()
+}
+
+// Len returns the number of spooled events.
+func (s *Spool) Len() int {
+	return int(s.size.Load())
+}
+
+// Dropped returns the number of events that have been rejected because the
+// spool was full.
+func (s *Spool) Dropped() int {
+	return int(s.dropped.Load())
+}
+
+// Push appends evt to the spool.
+func (s *Spool) Push(evt *eventsv1.Event) error {
+	blob, err := proto.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal protobuf: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if s.maxSize > 0 && s.Len() >= s.maxSize {
+			s.dropped.Add(1)
+
+			return ErrSpoolFull
+		}
+
+		bucket := tx.Bucket(spoolBucket)
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+
+		if err := bucket.Put(key, blob); err != nil {
+			return err
+		}
+
+		s.size.Add(1)
+
+		return nil
+	})
+}
+
+// Flush calls fn for at most limit spooled events in the order they have been
+// pushed and returns the number of flushed events. A limit <= 0 flushes all
+// events. Events are removed from the spool once fn returns successfully.
+// Flush stops at the first error returned by fn and returns it.
+func (s *Spool) Flush(limit int, fn func(*eventsv1.Event) error) (int, error) {
+	for n := 0; ; n++ {
+		if limit > 0 && n >= limit {
+			return n, nil
+		}
+
+		var (
+			key []byte
+			evt *eventsv1.Event
+		)
+
+		if err := s.db.View(func(tx *bolt.Tx) error {
+			k, v := tx.Bucket(spoolBucket).Cursor().First()
+			if k == nil {
+				return nil
+			}
+
+			key = append([]byte(nil), k...)
+			evt = new(eventsv1.Event)
+
+			return proto.Unmarshal(v, evt)
+		}); err != nil {
+			return n, err
+		}
+
+		if key == nil {
+			return n, nil
+		}
+
+		if err := fn(evt); err != nil {
+			return n, err
+		}
+
+		if err := s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(spoolBucket).Delete(key)
+		}); err != nil {
+			return n, err
+		}
+
+		s.size.Add(-1)
+	}
+}
@@ -0,0 +1,160 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type fakeClient struct {
+	l         sync.Mutex
+	fail      bool
+	published []*eventsv1.Event
+}
+
+func (f *fakeClient) setFail(fail bool) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	f.fail = fail
+}
+
+func (f *fakeClient) Subscribe(string, byte, mqtt.MessageHandler) error { return nil }
+func (f *fakeClient) Unsubscribe(...string) error                       { return nil }
+
+func (f *fakeClient) Publish(_ string, _ byte, _ bool, payload []byte) error {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if f.fail {
+		return errors.New("not connected")
+	}
+
+	evt := new(eventsv1.Event)
+	if err := proto.Unmarshal(payload, evt); err != nil {
+		return err
+	}
+
+	f.published = append(f.published, evt)
+
+	return nil
+}
+
+func makeEvent(t *testing.T, topic string) *eventsv1.Event {
+	t.Helper()
+
+	evt, err := anypb.New(&eventsv1.SubscribeRequest{
+		Kind: &eventsv1.SubscribeRequest_Subscribe{
+			Subscribe: topic,
+		},
+	})
+	require.NoError(t, err)
+
+	return &eventsv1.Event{Event: evt}
+}
+
+func published(cli *fakeClient) []string {
+	cli.l.Lock()
+	defer cli.l.Unlock()
+
+	var result []string
+	for _, evt := range cli.published {
+		var msg eventsv1.SubscribeRequest
+		if err := evt.Event.UnmarshalTo(&msg); err == nil {
+			result = append(result, msg.GetSubscribe())
+		}
+	}
+
+	return result
+}
+
+func TestPublishSpool(t *testing.T) {
+	spool, err := OpenSpool(filepath.Join(t.TempDir(), "spool.db"), 3)
+	require.NoError(t, err)
+	defer spool.Close()
+
+	b, err := NewBroker(context.Background(), nil, WithSpool(spool))
+	require.NoError(t, err)
+
+	// not yet connected, events are spooled
+	require.NoError(t, b.Publish(makeEvent(t, "1")))
+	require.NoError(t, b.Publish(makeEvent(t, "2")))
+	require.Equal(t, 2, spool.Len())
+
+	// publishing fails, events are spooled
+	cli := &fakeClient{fail: true}
+	b.conn = cli
+
+	require.NoError(t, b.Publish(makeEvent(t, "3")))
+	require.Equal(t, 3, spool.Len())
+
+	// the spool is full
+	require.ErrorIs(t, b.Publish(makeEvent(t, "4")), ErrSpoolFull)
+	require.Equal(t, 1, spool.Dropped())
+
+	// once connected, spooled events are flushed in the background
+	cli.setFail(false)
+	b.startFlush()
+
+	require.Eventually(t, func() bool {
+		return spool.Len() == 0
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, b.Publish(makeEvent(t, "5")))
+
+	require.Equal(t, []string{"1", "2", "3", "5"}, published(cli))
+}
+
+func TestFlushSpoolInBatches(t *testing.T) {
+	spool, err := OpenSpool(filepath.Join(t.TempDir(), "spool.db"), 0)
+	require.NoError(t, err)
+	defer spool.Close()
+
+	b, err := NewBroker(context.Background(), nil, WithSpool(spool))
+	require.NoError(t, err)
+
+	for i := 0; i < 2*spoolBatchSize+1; i++ {
+		require.NoError(t, b.Publish(makeEvent(t, strconv.Itoa(i))))
+	}
+
+	n, err := spool.Flush(spoolBatchSize, func(*eventsv1.Event) error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, spoolBatchSize, n)
+	require.Equal(t, spoolBatchSize+1, spool.Len())
+
+	// events published while the spool is flushed are queued behind the
+	// spooled events
+	cli := &fakeClient{}
+
+	b.connLock.Lock()
+	b.conn = cli
+	b.connLock.Unlock()
+
+	require.NoError(t, b.Publish(makeEvent(t, "last")))
+
+	require.Eventually(t, func() bool {
+		return spool.Len() == 0
+	}, time.Second, time.Millisecond)
+
+	result := published(cli)
+	require.Len(t, result, spoolBatchSize+2)
+	require.Equal(t, strconv.Itoa(spoolBatchSize), result[0])
+	require.Equal(t, "last", result[len(result)-1])
+}
+
+func TestPublishWithoutSpool(t *testing.T) {
+	b, err := NewBroker(context.Background(), nil)
+	require.NoError(t, err)
+
+	require.Error(t, b.Publish(makeEvent(t, "1")))
+}
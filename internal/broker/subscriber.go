@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 
 	connect "github.com/bufbuild/connect-go"
 	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
@@ -21,6 +22,9 @@ type Subscriber struct {
 	stream SubscriberStream
 	broker *Broker
 	log    *slog.Logger
+
+	kind string
+	user string
 }
 
 func NewSubscriber(stream SubscriberStream, broker *Broker) *Subscriber {
@@ -28,16 +32,51 @@ func NewSubscriber(stream SubscriberStream, broker *Broker) *Subscriber {
 		stream: stream,
 		broker: broker,
 		log:    slog.Default().With("subsystem", "subscriber", "peer", stream.Peer().Addr),
+		kind:   "subscribe",
 	}
 }
 
+// WithClientInfo sets the kind of the subscription and the authenticated user
+// as shown by Broker.Clients.
+func (s *Subscriber) WithClientInfo(kind, user string) *Subscriber {
+	s.kind = kind
+	s.user = user
+
+	return s
+}
+
 func (s *Subscriber) Handle(ctx context.Context) error {
 	msgs := make(chan *eventsv1.Event, 100)
 
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	id, unregister := s.broker.RegisterClient(msgs, ClientInfo{
+		Kind: s.kind,
+		Peer: s.stream.Peer().Addr,
+		User: s.user,
+	}, func() {
+		cancel(ErrDisconnected)
+	})
+	defer unregister()
+
+	s.log = s.log.With("id", id)
+
+	// closed is set once msgs is about to be closed so the receive loop
+	// does not subscribe it again.
+	var (
+		closeLock sync.Mutex
+		closed    bool
+	)
+
 	go func() {
 		// wait for the connection to complete
 		<-ctx.Done()
 
+		closeLock.Lock()
+		closed = true
+		closeLock.Unlock()
+
 		// unsubscribe from the broker, once returned
 		// msgs cannot be used again by the broker and we are
 		// safe to close it
@@ -46,6 +85,19 @@ func (s *Subscriber) Handle(ctx context.Context) error {
 		close(msgs)
 	}()
 
+	subscribe := func(typeUrl string) bool {
+		closeLock.Lock()
+		defer closeLock.Unlock()
+
+		if closed {
+			return false
+		}
+
+		s.broker.Subscribe(typeUrl, msgs)
+
+		return true
+	}
+
 	go func() {
 		defer s.log.Debug("receive loop finished")
 
@@ -62,7 +114,13 @@ func (s *Subscriber) Handle(ctx context.Context) error {
 			switch v := msg.Kind.(type) {
 			case *eventsv1.SubscribeRequest_Subscribe:
 				s.log.Debug("subscribing to topic", "topic", v.Subscribe)
-				s.broker.Subscribe(v.Subscribe, msgs)
+				if !subscribe(v.Subscribe) {
+					return
+				}
+
+			case *eventsv1.SubscribeRequest_Unsubscribe:
+				s.log.Debug("unsubscribing from topic", "topic", v.Unsubscribe)
+				s.broker.Unsubscribe(v.Unsubscribe, msgs)
 
 			default:
 				s.log.Error("unhandled message", "type", fmt.Sprintf("%T", msg.Kind))
@@ -70,7 +128,14 @@ func (s *Subscriber) Handle(ctx context.Context) error {
 		}
 	}()
 
+	// msgs is drained until it is closed so the broker never blocks on a
+	// subscription that is being torn down.
+	var failed bool
 	for m := range msgs {
+		if failed {
+			continue
+		}
+
 		if err := s.stream.Send(m); err != nil {
 			if !errors.Is(err, io.EOF) {
 				s.log.Error("failed to send message over stream", "error", err.Error())
@@ -78,11 +143,16 @@ func (s *Subscriber) Handle(ctx context.Context) error {
 				s.log.Info("client disconnected")
 			}
 
-			break
+			failed = true
+			cancel(nil)
 		}
 	}
 
 	s.log.Debug("subscription completed")
 
+	if errors.Is(context.Cause(ctx), ErrDisconnected) {
+		return connect.NewError(connect.CodeAborted, ErrDisconnected)
+	}
+
 	return nil
 }
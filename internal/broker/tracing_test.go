@@ -0,0 +1,62 @@
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	cli := &fakeClient{}
+
+	b, err := NewBroker(context.Background(), cli)
+	require.NoError(t, err)
+
+	typeUrl := "tkd.events.v1.SubscribeRequest"
+
+	msgs := make(chan *eventsv1.Event, 1)
+	b.Subscribe(typeUrl, msgs)
+
+	evt := makeEvent(t, "1")
+
+	ctx, publish := tracing.StartPublish(context.Background(), evt)
+	require.NoError(t, b.Publish(evt))
+	publish.End()
+
+	// deliver the published message back to the broker as if received from
+	// MQTT
+	payload, err := proto.Marshal(cli.published[0])
+	require.NoError(t, err)
+
+	b.handleMessage(nil, &retainedMessage{topic: makeTopic(typeUrl), payload: payload})
+
+	received := <-msgs
+
+	// the subscriber receives the context of the consumer span
+	sc := trace.SpanContextFromContext(tracing.Extract(context.Background(), received))
+	require.True(t, sc.IsValid())
+	require.Equal(t, trace.SpanContextFromContext(ctx).TraceID(), sc.TraceID())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	consumer := spans[1]
+	require.Equal(t, "receive "+evt.Event.TypeUrl, consumer.Name())
+	require.Equal(t, trace.SpanKindConsumer, consumer.SpanKind())
+	require.Equal(t, publish.SpanContext().SpanID(), consumer.Parent().SpanID())
+	require.Equal(t, consumer.SpanContext().SpanID(), sc.SpanID())
+}
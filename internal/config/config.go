@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"time"
 
 	"github.com/sethvargo/go-envconfig"
 )
@@ -16,8 +17,132 @@ type Config struct {
 	IdmURL        string `env:"IDM_URL"`
 	TypeServerURL string `env:"TYPE_SERVER"`
 
+	// AutomationReloadInterval is the interval at which AUTOMATION_PATH is
+	// checked for added, changed or removed bundles. Zero disables reloading.
+	AutomationReloadInterval time.Duration `env:"AUTOMATION_RELOAD_INTERVAL, default=5s"`
+
+	// AutomationLogSize is the number of console messages kept for each
+	// bundle. Messages older than AutomationLogMaxAge are dropped as well;
+	// zero keeps them until they are evicted by newer ones.
+	AutomationLogSize   int           `env:"AUTOMATION_LOG_SIZE, default=1000"`
+	AutomationLogMaxAge time.Duration `env:"AUTOMATION_LOG_MAX_AGE, default=24h"`
+
+	// AutomationHandlerTimeout is the maximum execution time of a single
+	// event or schedule handler. Handlers that exceed it are interrupted and
+	// treated as failed. Zero disables the timeout. A warning is logged for
+	// handlers that take longer than AutomationHandlerWarnThreshold. Both
+	// may be overwritten by bundles in package.json.
+	AutomationHandlerTimeout       time.Duration `env:"AUTOMATION_HANDLER_TIMEOUT, default=30s"`
+	AutomationHandlerWarnThreshold time.Duration `env:"AUTOMATION_HANDLER_WARN_THRESHOLD, default=5s"`
+
+	// AutomationLimits configures the resource limits of automation bundles.
+	// Bundles may declare lower limits in package.json.
+	AutomationLimits AutomationLimits `env:", prefix=AUTOMATION_LIMIT_"`
+
+	// WebhookConfig is the path to a JSON file that configures outgoing
+	// webhooks.
+	WebhookConfig string `env:"WEBHOOK_CONFIG"`
+
+	// HistoryPath is the path of the event history database. If empty,
+	// the event history and listing all retained events are disabled.
+	HistoryPath      string        `env:"HISTORY_PATH"`
+	HistoryRetention time.Duration `env:"HISTORY_RETENTION, default=720h"`
+
+	// DeadLetterPath is the path of the dead-letter database for events that
+	// automation bundles failed to handle. If empty, failed events are only
+	// logged.
+	DeadLetterPath string `env:"DEAD_LETTER_PATH"`
+
+	// StoragePath is the path of the key/value database used by the storage
+	// module of automation bundles. If empty, the storage module is not
+	// available.
+	StoragePath string `env:"STORAGE_PATH"`
+
+	// SpoolPath is the path of a database used to spool published events
+	// while MQTT is disconnected. If empty, publishing fails while
+	// disconnected.
+	SpoolPath      string `env:"SPOOL_PATH"`
+	SpoolMaxEvents int    `env:"SPOOL_MAX_EVENTS, default=10000"`
+
+	// RetainedKeys maps event type URLs to the path of a payload field that
+	// identifies an entity (e.g. "room_id"). For those types, the latest
+	// retained event is kept per entity instead of per type.
+	// format: <type-url>:<field-path>,...
+	RetainedKeys map[string]string `env:"RETAINED_KEYS"`
+
+	// TracingEndpoint is the URL of an OTLP/HTTP collector, e.g.
+	// http://otel-collector:4318. If empty, spans are not exported but trace
+	// context is still propagated.
+	TracingEndpoint    string  `env:"TRACING_ENDPOINT"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO, default=1"`
+
+	// RateLimits configures rate limits for publishers and subscribers.
+	RateLimits RateLimits `env:", prefix=RATE_LIMIT_"`
+
 	// format: <scheme>://<host>:<port>/<fully-qualified-protobuf-service-name>
 	ConnectServices []string `env:"SERVICES"`
+
+	// ConsulAddress is the address of the consul agent. If set, automation
+	// schedules are only executed on the replica that holds the
+	// LeaderElectionKey lock.
+	ConsulAddress     string `env:"CONSUL"`
+	LeaderElectionKey string `env:"LEADER_ELECTION_KEY, default=service/events-service/leader"`
+}
+
+// RateLimits configures token-bucket limits for publishing events and the
+// number of concurrent subscriptions. Rates are in events per second, a rate
+// or limit of zero disables the respective limit.
+type RateLimits struct {
+	PublishPerUser      float64 `env:"PUBLISH_PER_USER"`
+	PublishPerUserBurst int     `env:"PUBLISH_PER_USER_BURST, default=100"`
+
+	PublishPerPeer      float64 `env:"PUBLISH_PER_PEER"`
+	PublishPerPeerBurst int     `env:"PUBLISH_PER_PEER_BURST, default=100"`
+
+	PublishPerType      float64 `env:"PUBLISH_PER_TYPE"`
+	PublishPerTypeBurst int     `env:"PUBLISH_PER_TYPE_BURST, default=100"`
+
+	SubscriptionsPerUser int `env:"SUBSCRIPTIONS_PER_USER"`
+}
+
+// AutomationLimits holds the resource limits of an automation bundle. A zero
+// value disables the respective limit.
+//
+// Memory limits are not supported: goja does not account allocations per
+// runtime so the heap usage of a bundle cannot be told apart from the rest of
+// the process. Runaway handlers are stopped by the handler timeout instead.
+type AutomationLimits struct {
+	// Timers is the number of active timers and intervals.
+	Timers int `env:"TIMERS, default=1000" json:"timers"`
+
+	// Schedules is the number of schedules registered using schedule().
+	Schedules int `env:"SCHEDULES, default=100" json:"schedules"`
+
+	// ConcurrentCalls is the number of concurrent outbound HTTP and RPC
+	// calls.
+	ConcurrentCalls int `env:"CONCURRENT_CALLS, default=16" json:"concurrentCalls"`
+
+	// Subscriptions is the number of event handlers registered using on().
+	Subscriptions int `env:"SUBSCRIPTIONS, default=100" json:"subscriptions"`
+}
+
+// Cap returns l with each limit lowered to the one of upper. Limits that are
+// not set in l are taken from upper.
+func (l AutomationLimits) Cap(upper AutomationLimits) AutomationLimits {
+	return AutomationLimits{
+		Timers:          capLimit(l.Timers, upper.Timers),
+		Schedules:       capLimit(l.Schedules, upper.Schedules),
+		ConcurrentCalls: capLimit(l.ConcurrentCalls, upper.ConcurrentCalls),
+		Subscriptions:   capLimit(l.Subscriptions, upper.Subscriptions),
+	}
+}
+
+func capLimit(value, upper int) int {
+	if value <= 0 || (upper > 0 && value > upper) {
+		return upper
+	}
+
+	return value
 }
 
 func LoadConfig(ctx context.Context) (*Config, error) {
@@ -0,0 +1,195 @@
+package deadletter
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrNotFound = errors.New("dead letter not found")
+
+var bucketName = []byte("dead-letters")
+
+// Store persists events that failed to be handled by automation bundles in
+// an embedded bbolt database. Store implements automation.DeadLetterQueue.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the dead-letter database at path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter database: %w", err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	}); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put stores a new dead letter.
+func (s *Store) Put(letter automation.DeadLetter) error {
+	now := time.Now()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 16)
+		binary.BigEndian.PutUint64(key[:8], uint64(now.UnixNano()))
+		binary.BigEndian.PutUint64(key[8:], seq)
+
+		return put(bucket, key, &eventsservicev1.DeadLetter{
+			Id:              hex.EncodeToString(key),
+			Bundle:          letter.Bundle,
+			Subscription:    letter.Subscription,
+			Handler:         int32(letter.Handler),
+			HandlerId:       letter.HandlerID,
+			Event:           letter.Event.GetEvent(),
+			Retained:        letter.Event.GetRetained(),
+			Error:           letter.Error,
+			Stack:           letter.Stack,
+			CreateTime:      timestamppb.New(now),
+			LastFailureTime: timestamppb.New(now),
+		})
+	})
+}
+
+// List returns all dead letters, oldest first. If bundle is set, only dead
+// letters of that bundle are returned.
+func (s *Store) List(bundle string) ([]*eventsservicev1.DeadLetter, error) {
+	var result []*eventsservicev1.DeadLetter
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(_, v []byte) error {
+			letter := new(eventsservicev1.DeadLetter)
+			if err := proto.Unmarshal(v, letter); err != nil {
+				return err
+			}
+
+			if bundle == "" || letter.Bundle == bundle {
+				result = append(result, letter)
+			}
+
+			return nil
+		})
+	})
+
+	return result, err
+}
+
+// Get returns the dead letter with the given id.
+func (s *Store) Get(id string) (*eventsservicev1.DeadLetter, error) {
+	key, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var letter *eventsservicev1.DeadLetter
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get(key)
+		if v == nil {
+			return ErrNotFound
+		}
+
+		letter = new(eventsservicev1.DeadLetter)
+
+		return proto.Unmarshal(v, letter)
+	})
+
+	return letter, err
+}
+
+// Delete removes the dead letter with the given id.
+func (s *Store) Delete(id string) error {
+	key, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if bucket.Get(key) == nil {
+			return ErrNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
+
+// RecordFailure updates the dead letter with the given id after a failed
+// re-drive and returns the updated dead letter.
+func (s *Store) RecordFailure(id string, cause error) (*eventsservicev1.DeadLetter, error) {
+	key, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var letter *eventsservicev1.DeadLetter
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+
+		v := bucket.Get(key)
+		if v == nil {
+			return ErrNotFound
+		}
+
+		letter = new(eventsservicev1.DeadLetter)
+		if err := proto.Unmarshal(v, letter); err != nil {
+			return err
+		}
+
+		letter.Error, letter.Stack = automation.ErrorDetails(cause)
+		letter.LastFailureTime = timestamppb.Now()
+		letter.Redrives++
+
+		return put(bucket, key, letter)
+	})
+
+	return letter, err
+}
+
+func put(bucket *bolt.Bucket, key []byte, letter *eventsservicev1.DeadLetter) error {
+	blob, err := proto.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, blob)
+}
+
+func parseID(id string) ([]byte, error) {
+	key, err := hex.DecodeString(id)
+	if err != nil || len(key) != 16 {
+		return nil, fmt.Errorf("%w: invalid id %q", ErrNotFound, id)
+	}
+
+	return key, nil
+}
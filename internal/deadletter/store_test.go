@@ -0,0 +1,64 @@
+package deadletter
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestStore(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "dead-letters.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	payload, err := anypb.New(&eventsv1.SubscribeRequest{})
+	require.NoError(t, err)
+
+	for _, bundle := range []string{"a", "b", "a"} {
+		require.NoError(t, store.Put(automation.DeadLetter{
+			Bundle:       bundle,
+			Subscription: "tkd.events.v1.SubscribeRequest",
+			Handler:      1,
+			HandlerID:    "abc",
+			Event:        &eventsv1.Event{Event: payload, Retained: true},
+			Error:        "boom",
+		}))
+	}
+
+	all, err := store.List("")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+
+	letters, err := store.List("a")
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	require.Equal(t, int32(1), letters[0].Handler)
+	require.Equal(t, "abc", letters[0].HandlerId)
+	require.True(t, letters[0].Retained)
+
+	// record a failed re-drive
+	updated, err := store.RecordFailure(letters[0].Id, errors.New("still broken"))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), updated.Redrives)
+	require.Equal(t, "still broken", updated.Error)
+
+	letter, err := store.Get(letters[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), letter.Redrives)
+
+	// delete
+	require.NoError(t, store.Delete(letters[0].Id))
+	require.ErrorIs(t, store.Delete(letters[0].Id), ErrNotFound)
+
+	_, err = store.Get("invalid")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	all, err = store.List("")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+}
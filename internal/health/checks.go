@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Connected returns a check that fails if connected returns false.
+func Connected(name string, connected func() bool) CheckFunc {
+	return func(context.Context) error {
+		if !connected() {
+			return fmt.Errorf("%s: not connected", name)
+		}
+
+		return nil
+	}
+}
+
+// Reachable returns a check that fails if no HTTP response can be received
+// from url. The status code of the response is ignored.
+func Reachable(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return res.Body.Close()
+	}
+}
+
+// Failures collects named failures, for example automation bundles that
+// failed to load. The check fails while there are any failures.
+type Failures struct {
+	lock     sync.Mutex
+	failures map[string]error
+}
+
+// Set records a failure for name. A nil error removes the failure.
+func (f *Failures) Set(name string, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.failures == nil {
+		f.failures = make(map[string]error)
+	}
+
+	if err == nil {
+		delete(f.failures, name)
+	} else {
+		f.failures[name] = err
+	}
+}
+
+// Check implements CheckFunc.
+func (f *Failures) Check(context.Context) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if len(f.failures) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(f.failures))
+	for name := range f.failures {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	msgs := make([]string, len(names))
+	for idx, name := range names {
+		msgs[idx] = fmt.Sprintf("%s: %s", name, f.failures[name])
+	}
+
+	return errors.New(strings.Join(msgs, "; "))
+}
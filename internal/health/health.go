@@ -0,0 +1,195 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	grpchealth "github.com/bufbuild/connect-grpchealth-go"
+)
+
+// CheckTimeout is the maximum duration of a single check.
+var CheckTimeout = 5 * time.Second
+
+// CheckFunc reports an error if a dependency of the service is unhealthy.
+type CheckFunc func(ctx context.Context) error
+
+// Report holds the result of all checks.
+type Report struct {
+	// Ready is true if all checks, except informational ones, succeeded.
+	Ready bool `json:"ready"`
+
+	// Checks holds "ok" or the error message of each check.
+	Checks map[string]string `json:"checks"`
+}
+
+// Checker runs a set of named checks to determine whether the service is
+// ready. Checker implements grpchealth.Checker.
+type Checker struct {
+	lock          sync.RWMutex
+	checks        map[string]CheckFunc
+	informational map[string]bool
+	services      map[string]struct{}
+
+	log *slog.Logger
+}
+
+// New returns a new checker. services holds the fully-qualified names of
+// the protobuf services that are reported by the gRPC health protocol.
+func New(services ...string) *Checker {
+	c := &Checker{
+		checks:        make(map[string]CheckFunc),
+		informational: make(map[string]bool),
+		services:      make(map[string]struct{}, len(services)),
+		log:           slog.Default().With("subsystem", "health"),
+	}
+
+	for _, svc := range services {
+		c.services[svc] = struct{}{}
+	}
+
+	return c
+}
+
+// Add adds a new check. An existing check with the same name is replaced.
+func (c *Checker) Add(name string, check CheckFunc) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.checks[name] = check
+	delete(c.informational, name)
+}
+
+// AddInformational adds a check that is included in the report but does not
+// affect the readiness of the service. An existing check with the same name
+// is replaced.
+func (c *Checker) AddInformational(name string, check CheckFunc) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.checks[name] = check
+	c.informational[name] = true
+}
+
+// Run executes all checks concurrently and returns the report.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.lock.RLock()
+	checks := make(map[string]CheckFunc, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+
+	informational := make(map[string]bool, len(c.informational))
+	for name := range c.informational {
+		informational[name] = true
+	}
+	c.lock.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		report = Report{
+			Ready:  true,
+			Checks: make(map[string]string, len(checks)),
+		}
+	)
+
+	for name, check := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := "ok"
+			if err := check(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if result != "ok" && !informational[name] {
+				report.Ready = false
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// Check implements grpchealth.Checker. The status of the whole process and
+// of all registered services reflects the readiness of the service.
+func (c *Checker) Check(ctx context.Context, req *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	if req.Service != "" {
+		if _, ok := c.services[req.Service]; !ok {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("unknown service %s", req.Service))
+		}
+	}
+
+	status := grpchealth.StatusServing
+	if !c.Run(ctx).Ready {
+		status = grpchealth.StatusNotServing
+	}
+
+	return &grpchealth.CheckResponse{Status: status}, nil
+}
+
+// LivenessHandler returns a handler that always responds with 200 OK as
+// long as the process is able to serve requests. The body holds the current
+// report for informational purposes.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.writeReport(w, c.Run(r.Context()), http.StatusOK)
+	})
+}
+
+// ReadinessHandler returns a handler that responds with 503 Service
+// Unavailable if any check fails.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+
+		c.writeReport(w, report, status)
+	})
+}
+
+func (c *Checker) writeReport(w http.ResponseWriter, report Report, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		c.log.Error("failed to encode health report", "error", err)
+	}
+}
+
+// failedChecks returns the names of all failed checks in report.
+func failedChecks(report Report) []string {
+	var failed []string
+
+	for name, result := range report.Checks {
+		if result != "ok" {
+			failed = append(failed, name)
+		}
+	}
+
+	sort.Strings(failed)
+
+	return failed
+}
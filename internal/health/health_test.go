@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	grpchealth "github.com/bufbuild/connect-grpchealth-go"
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/apis/pkg/discovery"
+)
+
+func TestReadiness(t *testing.T) {
+	var (
+		connected atomic.Bool
+		failures  Failures
+	)
+
+	checker := New("tkd.events.v1.EventService")
+	checker.Add("mqtt", Connected("mqtt", connected.Load))
+	checker.AddInformational("automation", failures.Check)
+
+	srv := httptest.NewServer(checker.ReadinessHandler())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	connected.Store(true)
+
+	res, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// informational checks are reported but do not affect readiness
+	failures.Set("bundle-a", errors.New("syntax error"))
+
+	report := checker.Run(context.Background())
+	require.True(t, report.Ready)
+	require.Equal(t, "ok", report.Checks["mqtt"])
+	require.Equal(t, "bundle-a: syntax error", report.Checks["automation"])
+
+	connected.Store(false)
+
+	status, err := checker.Check(context.Background(), &grpchealth.CheckRequest{Service: "tkd.events.v1.EventService"})
+	require.NoError(t, err)
+	require.Equal(t, grpchealth.StatusNotServing, status.Status)
+
+	_, err = checker.Check(context.Background(), &grpchealth.CheckRequest{Service: "unknown"})
+	require.Error(t, err)
+
+	connected.Store(true)
+
+	status, err = checker.Check(context.Background(), &grpchealth.CheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, grpchealth.StatusServing, status.Status)
+}
+
+type fakeCatalog struct {
+	discovery.Discoverer
+
+	lock       sync.Mutex
+	registered bool
+	healthy    int
+}
+
+func (f *fakeCatalog) Register(context.Context, discovery.ServiceInstance) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.registered = true
+
+	return nil
+}
+
+func (f *fakeCatalog) Deregister(context.Context, discovery.ServiceInstance) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.registered = false
+
+	return nil
+}
+
+func (f *fakeCatalog) MarkHealthy(context.Context, discovery.ServiceInstance) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.healthy++
+
+	return nil
+}
+
+func TestRegisterFollowsReadiness(t *testing.T) {
+	var ready atomic.Bool
+
+	checker := New()
+	checker.Add("ready", Connected("test", ready.Load))
+
+	catalog := &fakeCatalog{}
+	instance := discovery.ServiceInstance{Name: "test", Address: "localhost:8080"}
+
+	// not ready, the instance must not be registered
+	require.False(t, checker.sync(context.Background(), catalog, instance, false))
+	require.False(t, catalog.registered)
+
+	ready.Store(true)
+	require.True(t, checker.sync(context.Background(), catalog, instance, false))
+	require.True(t, catalog.registered)
+	require.Equal(t, 1, catalog.healthy)
+
+	ready.Store(false)
+	require.False(t, checker.sync(context.Background(), catalog, instance, true))
+	require.False(t, catalog.registered)
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/tierklinik-dobersberg/apis/pkg/discovery"
+)
+
+// RegisterInterval is the interval at which the readiness is evaluated and
+// the service catalog is updated.
+var RegisterInterval = 5 * time.Second
+
+// Register registers instance at the service catalog while the service is
+// ready and removes the registration as soon as any check fails. Like
+// discovery.Register, the instance is marked healthy periodically and
+// deregistered once ctx is cancelled.
+func (c *Checker) Register(ctx context.Context, discoverer discovery.Discoverer, instance discovery.ServiceInstance) error {
+	host, port, err := net.SplitHostPort(instance.Address)
+	if err != nil {
+		return fmt.Errorf("%s: expected ServiceInstance.Address to be <host>:<port>", instance.Name)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	if host == "" {
+		host = hostname
+	}
+
+	if instance.Instance == "" {
+		instance.Instance = hostname
+	}
+
+	instance.Address = net.JoinHostPort(host, port)
+
+	go func() {
+		ticker := time.NewTicker(RegisterInterval)
+		defer ticker.Stop()
+
+		registered := false
+
+		defer func() {
+			if !registered {
+				return
+			}
+
+			// ctx is already cancelled at this point
+			if err := discoverer.Deregister(context.Background(), instance); err != nil {
+				c.log.Error("failed to deregister service from catalog", "error", err, "instance", instance.Instance)
+			}
+		}()
+
+		for {
+			registered = c.sync(ctx, discoverer, instance, registered)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// sync updates the catalog registration of instance based on the current
+// readiness and reports whether the instance is registered afterwards.
+func (c *Checker) sync(ctx context.Context, discoverer discovery.Discoverer, instance discovery.ServiceInstance, registered bool) bool {
+	report := c.Run(ctx)
+
+	switch {
+	case report.Ready && !registered:
+		if err := discoverer.Register(ctx, instance); err != nil {
+			c.log.Error("failed to register service instance", "error", err, "instance", instance.Instance)
+
+			return false
+		}
+
+		c.log.Info("service is ready, registered at service catalog", "instance", instance.Instance)
+
+	case !report.Ready && registered:
+		if err := discoverer.Deregister(ctx, instance); err != nil {
+			c.log.Error("failed to deregister service instance", "error", err, "instance", instance.Instance)
+
+			return true
+		}
+
+		c.log.Warn("service is not ready, removed from service catalog", "instance", instance.Instance, "failed", failedChecks(report))
+
+		return false
+
+	case !report.Ready:
+		return false
+	}
+
+	if err := discoverer.MarkHealthy(ctx, instance); err != nil {
+		c.log.Error("failed to mark service instance as healthy", "error", err, "instance", instance.Instance)
+	}
+
+	return true
+}
@@ -0,0 +1,357 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"github.com/tierklinik-dobersberg/events-service/internal/codec"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+type Broker interface {
+	Subscribe(string, chan *eventsv1.Event)
+	UnsubscribeAll(chan *eventsv1.Event)
+	RetainedKey(*eventsv1.Event) string
+}
+
+// retainedBucket maps the type URL and retained key of each entity to the
+// key of it's latest recorded retained event. The name cannot collide with
+// the bucket of an event type.
+var retainedBucket = []byte("\x00retained")
+
+// Store records events in an embedded bbolt database. Events are stored
+// in one bucket per event type keyed by their receive time.
+type Store struct {
+	db    *bolt.DB
+	codec *codec.JSON
+	log   *slog.Logger
+}
+
+// Open opens or creates the history database at path. resolver is used to
+// convert events to JSON when applying filters and may be nil.
+func Open(path string, resolver codec.Resolver) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	return &Store{
+		db:    db,
+		codec: codec.NewCodec(resolver),
+		log:   slog.Default().With("subsystem", "history"),
+	}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Run subscribes to all events published at broker and records them until
+// ctx is cancelled. Events older than retention are removed periodically.
+func (s *Store) Run(ctx context.Context, b Broker, retention time.Duration) {
+	msgs := make(chan *eventsv1.Event, 100)
+
+	b.Subscribe(broker.Wildcard, msgs)
+	defer b.UnsubscribeAll(msgs)
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if retention <= 0 {
+				continue
+			}
+
+			count, err := s.Prune(time.Now().Add(-retention))
+			if err != nil {
+				s.log.Error("failed to prune event history", "error", err)
+			} else if count > 0 {
+				s.log.Info("pruned event history", "count", count)
+			}
+
+		case evt := <-msgs:
+			var key string
+			if evt.Retained {
+				key = b.RetainedKey(evt)
+			}
+
+			if err := s.Record(evt, key, time.Now()); err != nil {
+				s.log.Error("failed to record event", "typeUrl", evt.GetEvent().GetTypeUrl(), "error", err)
+			}
+		}
+	}
+}
+
+// Record stores evt. Retained events are only stored if they differ from
+// the latest retained event of the same type and retainedKey since MQTT
+// re-delivers retained messages on each (re-)subscription. retainedKey is
+// the entity key returned by broker.Broker.RetainedKey and empty for types
+// without a configured key.
+func (s *Store) Record(evt *eventsv1.Event, retainedKey string, receiveTime time.Time) error {
+	if evt.GetEvent() == nil {
+		return fmt.Errorf("missing event field")
+	}
+
+	typeUrl := normalizeTypeUrl(evt.Event.TypeUrl)
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(typeUrl))
+		if err != nil {
+			return err
+		}
+
+		index, err := tx.CreateBucketIfNotExists(retainedBucket)
+		if err != nil {
+			return err
+		}
+
+		indexKey := []byte(typeUrl + "\x00" + retainedKey)
+
+		if evt.Retained {
+			// the event may have been pruned in the meantime
+			if last := index.Get(indexKey); last != nil {
+				var stored eventsservicev1.StoredEvent
+				if blob := bucket.Get(last); blob != nil && proto.Unmarshal(blob, &stored) == nil && proto.Equal(stored.Event, evt.Event) {
+					return nil
+				}
+			}
+		}
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 16)
+		binary.BigEndian.PutUint64(key[:8], uint64(receiveTime.UnixNano()))
+		binary.BigEndian.PutUint64(key[8:], seq)
+
+		blob, err := proto.Marshal(&eventsservicev1.StoredEvent{
+			Id:          typeUrl + "/" + hex.EncodeToString(key),
+			ReceiveTime: timestamppb.New(receiveTime),
+			Event:       evt.Event,
+			Retained:    evt.Retained,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put(key, blob); err != nil {
+			return err
+		}
+
+		if evt.Retained {
+			return index.Put(indexKey, key)
+		}
+
+		return nil
+	})
+}
+
+// Query describes which events should be returned by Store.List.
+type Query struct {
+	TypeURL string
+	From    time.Time
+	To      time.Time
+	Limit   int
+
+	// Filter is matched against the JSON representation of the event.
+	// See FilterFromJSON.
+	Filter map[string]any
+}
+
+// List returns all events matching q ordered by their receive time.
+func (s *Store) List(q Query) ([]*eventsservicev1.StoredEvent, error) {
+	var result []*eventsservicev1.StoredEvent
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(normalizeTypeUrl(q.TypeURL)))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+
+		var k, v []byte
+		if q.From.IsZero() {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(timeKey(q.From))
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if !q.To.IsZero() && binary.BigEndian.Uint64(k[:8]) >= uint64(q.To.UnixNano()) {
+				break
+			}
+
+			stored := new(eventsservicev1.StoredEvent)
+			if err := proto.Unmarshal(v, stored); err != nil {
+				return err
+			}
+
+			if len(q.Filter) > 0 {
+				ok, err := s.matches(stored, q.Filter)
+				if err != nil {
+					s.log.Warn("failed to apply filter", "id", stored.Id, "error", err)
+					continue
+				}
+
+				if !ok {
+					continue
+				}
+			}
+
+			result = append(result, stored)
+
+			if q.Limit > 0 && len(result) >= q.Limit {
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Latest returns the last count events of typeUrl, newest first.
+func (s *Store) Latest(typeUrl string, count int) ([]*eventsservicev1.StoredEvent, error) {
+	var result []*eventsservicev1.StoredEvent
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(normalizeTypeUrl(typeUrl)))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil && len(result) < count; k, v = c.Prev() {
+			stored := new(eventsservicev1.StoredEvent)
+			if err := proto.Unmarshal(v, stored); err != nil {
+				return err
+			}
+
+			result = append(result, stored)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Prune removes all events received before the given time and returns the
+// number of removed events.
+func (s *Store) Prune(before time.Time) (int, error) {
+	var count int
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			if bytes.Equal(name, retainedBucket) {
+				return nil
+			}
+
+			end := timeKey(before)
+
+			c := bucket.Cursor()
+			for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.First() {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+
+				count++
+			}
+
+			return nil
+		})
+	})
+
+	return count, err
+}
+
+func (s *Store) matches(stored *eventsservicev1.StoredEvent, filter map[string]any) (bool, error) {
+	blob, err := s.codec.Marshal(stored.Event)
+	if err != nil {
+		return false, err
+	}
+
+	var value map[string]any
+	if err := json.Unmarshal(blob, &value); err != nil {
+		return false, err
+	}
+
+	return containsAll(value, filter), nil
+}
+
+// FilterFromJSON parses a JSON object filter. An event matches the filter
+// if it's JSON representation contains all fields of the filter with equal
+// values.
+func FilterFromJSON(filter string) (map[string]any, error) {
+	if filter == "" {
+		return nil, nil
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal([]byte(filter), &result); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+	}
+
+	return result, nil
+}
+
+func containsAll(value map[string]any, filter map[string]any) bool {
+	for key, expected := range filter {
+		actual, ok := value[key]
+		if !ok {
+			return false
+		}
+
+		expectedObj, isObj := expected.(map[string]any)
+		actualObj, actualIsObj := actual.(map[string]any)
+
+		switch {
+		case isObj && actualIsObj:
+			if !containsAll(actualObj, expectedObj) {
+				return false
+			}
+
+		case !reflect.DeepEqual(expected, actual):
+			return false
+		}
+	}
+
+	return true
+}
+
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+
+	return key
+}
+
+func normalizeTypeUrl(typeUrl string) string {
+	return strings.TrimPrefix(typeUrl, "type.googleapis.com/")
+}
@@ -0,0 +1,128 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func makeEvent(t *testing.T, topic string) *eventsv1.Event {
+	t.Helper()
+
+	evt, err := anypb.New(&eventsv1.SubscribeRequest{
+		Kind: &eventsv1.SubscribeRequest_Subscribe{
+			Subscribe: topic,
+		},
+	})
+	require.NoError(t, err)
+
+	return &eventsv1.Event{Event: evt}
+}
+
+func TestStore(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"), nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	start := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		evt := makeEvent(t, fmt.Sprintf("topic-%d", i%2))
+
+		require.NoError(t, store.Record(evt, "", start.Add(time.Duration(i)*time.Hour)))
+	}
+
+	// all events
+	events, err := store.List(Query{TypeURL: "tkd.events.v1.SubscribeRequest"})
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+
+	// time range
+	events, err = store.List(Query{
+		TypeURL: "type.googleapis.com/tkd.events.v1.SubscribeRequest",
+		From:    start.Add(time.Hour),
+		To:      start.Add(3 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, start.Add(time.Hour), events[0].ReceiveTime.AsTime())
+
+	// filter and limit
+	filter, err := FilterFromJSON(`{"subscribe": "topic-1"}`)
+	require.NoError(t, err)
+
+	events, err = store.List(Query{TypeURL: "tkd.events.v1.SubscribeRequest", Filter: filter})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	events, err = store.List(Query{TypeURL: "tkd.events.v1.SubscribeRequest", Filter: filter, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// latest
+	events, err = store.Latest("tkd.events.v1.SubscribeRequest", 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, start.Add(4*time.Hour), events[0].ReceiveTime.AsTime())
+
+	// prune
+	count, err := store.Prune(start.Add(2 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	events, err = store.List(Query{TypeURL: "tkd.events.v1.SubscribeRequest"})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+}
+
+func TestRecordRetainedOnce(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"), nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	evt := makeEvent(t, "topic")
+	evt.Retained = true
+
+	// MQTT re-delivers retained messages on each subscription
+	require.NoError(t, store.Record(evt, "", time.Now()))
+	require.NoError(t, store.Record(evt, "", time.Now()))
+
+	events, err := store.Latest("tkd.events.v1.SubscribeRequest", 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.True(t, events[0].Retained)
+
+	// the retained state of each entity is de-duplicated on it's own
+	room1 := makeEvent(t, "room-1")
+	room1.Retained = true
+
+	room2 := makeEvent(t, "room-2")
+	room2.Retained = true
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, store.Record(room1, "room-1", time.Now()))
+		require.NoError(t, store.Record(room2, "room-2", time.Now()))
+	}
+
+	events, err = store.Latest("tkd.events.v1.SubscribeRequest", 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	// events are recorded again once they have been pruned
+	_, err = store.Prune(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Record(room1, "room-1", time.Now()))
+
+	events, err = store.Latest("tkd.events.v1.SubscribeRequest", 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	_, err = FilterFromJSON("not-json")
+	require.ErrorIs(t, err, ErrInvalidFilter)
+}
@@ -0,0 +1,115 @@
+package httpauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ErrUnauthenticated is returned by Authenticate if a request does not carry
+// an authenticated user.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator authenticates plain HTTP requests (like Server-Sent Events or
+// WebSocket upgrades) by running the auth interceptor of the connect handlers
+// for the RPC the endpoint mirrors. Requests are thus subject to the same
+// role resolution and auth annotations as the RPC itself.
+type Authenticator struct {
+	client *connect.Client[emptypb.Empty, emptypb.Empty]
+}
+
+// userKey is used to hand the remote user from the innermost interceptor
+// back to Authenticate.
+type userKey struct{}
+
+// New returns a new authenticator that runs interceptor, usually created by
+// auth.NewAuthAnnotationInterceptor, for procedure.
+func New(procedure string, interceptor connect.Interceptor) *Authenticator {
+	// the terminal interceptor never calls next so the client does not
+	// perform any requests.
+	capture := connect.UnaryInterceptorFunc(func(connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+			if user := auth.From(ctx); user != nil {
+				*ctx.Value(userKey{}).(*auth.RemoteUser) = *user
+			}
+
+			return connect.NewResponse(&emptypb.Empty{}), nil
+		}
+	})
+
+	return &Authenticator{
+		client: connect.NewClient[emptypb.Empty, emptypb.Empty](
+			http.DefaultClient,
+			"http://localhost"+procedure,
+			connect.WithInterceptors(interceptor, capture),
+		),
+	}
+}
+
+// Authenticate returns the remote user of r. It returns ErrUnauthenticated if
+// the request does not carry a user ID and the error of the auth interceptor
+// if the user is not allowed to call the RPC.
+//
+// A nil *Authenticator does not require authentication and returns an
+// anonymous user since the X-Remote-User-ID header can be set by anyone if
+// there is no auth proxy in front of the service.
+func (a *Authenticator) Authenticate(r *http.Request) (auth.RemoteUser, error) {
+	if a == nil {
+		return auth.RemoteUser{}, nil
+	}
+
+	user, err := a.User(r.Context(), r.Header)
+	if err != nil {
+		return auth.RemoteUser{}, err
+	}
+
+	if user.ID == "" {
+		return auth.RemoteUser{}, ErrUnauthenticated
+	}
+
+	return user, nil
+}
+
+// User returns the remote user of a request with the given header. Unlike
+// Authenticate, User does not require a user ID and returns an anonymous
+// user instead. A nil *Authenticator always returns an anonymous user.
+func (a *Authenticator) User(ctx context.Context, header http.Header) (auth.RemoteUser, error) {
+	if a == nil {
+		return auth.RemoteUser{}, nil
+	}
+
+	req := connect.NewRequest(&emptypb.Empty{})
+	for key, values := range header {
+		req.Header()[key] = values
+	}
+
+	var user auth.RemoteUser
+
+	ctx = context.WithValue(ctx, userKey{}, &user)
+	if _, err := a.client.CallUnary(ctx, req); err != nil {
+		return auth.RemoteUser{}, err
+	}
+
+	return user, nil
+}
+
+// StatusCode returns the HTTP status code that should be used to reply to
+// a request that failed authentication with err.
+func StatusCode(err error) int {
+	var cerr *connect.Error
+
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.As(err, &cerr) && cerr.Code() == connect.CodePermissionDenied:
+		return http.StatusForbidden
+	case errors.As(err, &cerr) && cerr.Code() == connect.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,97 @@
+package httpauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/require"
+	eventsv1connect "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1/eventsv1connect"
+	idmv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1/idmv1connect"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func resolveRole(_ context.Context, id string) (*idmv1.Role, error) {
+	return &idmv1.Role{Id: id, Name: id}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := New(eventsv1connect.EventServiceSubscribeProcedure, auth.NewAuthAnnotationInterceptor(protoregistry.GlobalFiles, resolveRole, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := a.Authenticate(req)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+	require.Equal(t, http.StatusUnauthorized, StatusCode(err))
+
+	// User does not require a user ID
+	anonymous, err := a.User(req.Context(), req.Header)
+	require.NoError(t, err)
+	require.Empty(t, anonymous.ID)
+
+	req.Header.Set("X-Remote-User-ID", "alice")
+	req.Header.Set("X-Remote-User", "alice-name")
+	req.Header.Set("X-Remote-Role", "staff")
+
+	user, err := a.Authenticate(req)
+	require.NoError(t, err)
+	require.Equal(t, "alice", user.ID)
+	require.Equal(t, "alice-name", user.Username)
+	require.Equal(t, []*idmv1.Role{{Id: "staff", Name: "staff"}}, user.ResolvedRoles)
+}
+
+func TestAuthenticateRoles(t *testing.T) {
+	// CreateRole requires one of the admin roles of the RoleService
+	a := New(idmv1connect.RoleServiceCreateRoleProcedure, auth.NewAuthAnnotationInterceptor(protoregistry.GlobalFiles, resolveRole, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Remote-User-ID", "alice")
+	req.Header.Set("X-Remote-Role", "staff")
+
+	_, err := a.Authenticate(req)
+	require.Error(t, err)
+	require.Equal(t, http.StatusForbidden, StatusCode(err))
+
+	req.Header.Set("X-Remote-Role", "idm_superuser")
+
+	user, err := a.Authenticate(req)
+	require.NoError(t, err)
+	require.True(t, user.Admin)
+}
+
+func TestAuthenticateCustomExtractor(t *testing.T) {
+	type key struct{}
+
+	extractor := func(ctx context.Context, req connect.AnyRequest) (auth.RemoteUser, error) {
+		if ctx.Value(key{}) == "admin" {
+			return auth.RemoteUser{ID: "service-account"}, nil
+		}
+
+		return auth.RemoteHeaderExtractor(ctx, req)
+	}
+
+	a := New(eventsv1connect.EventServiceSubscribeProcedure, auth.NewAuthAnnotationInterceptor(protoregistry.GlobalFiles, resolveRole, extractor))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), key{}, "admin"))
+
+	user, err := a.Authenticate(req)
+	require.NoError(t, err)
+	require.Equal(t, "service-account", user.ID)
+}
+
+func TestNilAuthenticator(t *testing.T) {
+	var a *Authenticator
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Remote-User-ID", "alice")
+
+	// the header is not trusted without an auth proxy
+	user, err := a.Authenticate(req)
+	require.NoError(t, err)
+	require.Empty(t, user.ID)
+}
@@ -0,0 +1,109 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// Consul is an Elector that uses a Consul KV lock to elect a leader. The
+// leadership is bound to a Consul session so it is released automatically
+// if the current leader disappears without unlocking.
+type Consul struct {
+	client   *consul.Client
+	key      string
+	instance string
+
+	leader atomic.Bool
+	log    *slog.Logger
+}
+
+// NewConsul returns a new consul based leader elector that uses the consul
+// agent at addr and competes for the lock at key. instance is stored as the
+// lock value and should identify the current replica.
+// Call Run to actually start participating in the leader election.
+func NewConsul(addr, key, instance string) (*Consul, error) {
+	cfg := consul.DefaultConfig()
+	cfg.Address = addr
+
+	cli, err := consul.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %w", err)
+	}
+
+	return &Consul{
+		client:   cli,
+		key:      key,
+		instance: instance,
+		log:      slog.Default().With("subsystem", "leader", "key", key, "instance", instance),
+	}, nil
+}
+
+// Run participates in the leader election until ctx is cancelled. Once
+// the leadership is lost, Run will immediately try to acquire it again.
+func (c *Consul) Run(ctx context.Context) {
+	for {
+		if err := c.campaign(ctx); err != nil {
+			c.log.Error("leader election failed", "error", err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (c *Consul) campaign(ctx context.Context) error {
+	lock, err := c.client.LockOpts(&consul.LockOptions{
+		Key:            c.key,
+		Value:          []byte(c.instance),
+		SessionName:    "events-service-leader",
+		SessionTTL:     "15s",
+		MonitorRetries: 3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create lock: %w", err)
+	}
+
+	lostCh, err := lock.Lock(ctx.Done())
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	// lostCh is nil if ctx has been cancelled before we acquired the lock
+	if lostCh == nil {
+		return nil
+	}
+
+	c.leader.Store(true)
+	c.log.Info("acquired leadership")
+
+	select {
+	case <-lostCh:
+		c.log.Warn("lost leadership")
+	case <-ctx.Done():
+	}
+
+	c.leader.Store(false)
+
+	if err := lock.Unlock(); err != nil && err != consul.ErrLockNotHeld {
+		c.log.Error("failed to release leadership", "error", err)
+	}
+
+	return nil
+}
+
+func (c *Consul) IsLeader() bool {
+	return c.leader.Load()
+}
+
+var _ Elector = (*Consul)(nil)
@@ -0,0 +1,16 @@
+package leader
+
+// Elector decides which of multiple events-service replicas is responsible
+// for running singleton work like scheduled automation callbacks.
+type Elector interface {
+	// IsLeader reports whether the current process holds the leadership.
+	IsLeader() bool
+}
+
+// Always is an Elector that always reports leadership. It is used if
+// leader election is disabled, i.e. when only a single replica is running.
+type Always struct{}
+
+func (Always) IsLeader() bool { return true }
+
+var _ Elector = Always{}
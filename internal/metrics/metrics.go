@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "events_service"
+
+// OtherType is used as the value of the type label for event types that are
+// unknown to the service. This keeps the cardinality of the label bounded
+// since type URLs are supplied by clients.
+const OtherType = "other"
+
+var (
+	// EventsPublished counts events published to MQTT.
+	EventsPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "Number of events published to MQTT.",
+	}, []string{"broker", "type"})
+
+	// EventsReceived counts events received from MQTT.
+	EventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_received_total",
+		Help:      "Number of events received from MQTT.",
+	}, []string{"broker", "type"})
+
+	// EventsDelivered counts events dispatched to local subscribers.
+	EventsDelivered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_delivered_total",
+		Help:      "Number of events delivered to local subscribers.",
+	}, []string{"broker", "type"})
+
+	// EventsDropped counts events that could not be delivered because the
+	// subscriber was busy.
+	EventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_dropped_total",
+		Help:      "Number of events dropped because a subscriber was busy.",
+	}, []string{"broker", "type"})
+
+	// QueueFill observes the fill level of subscriber channels, as a ratio of
+	// their capacity, when dispatching an event.
+	QueueFill = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "subscriber_queue_fill_ratio",
+		Help:      "Fill level of subscriber channels when dispatching an event.",
+		Buckets:   []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 1},
+	}, []string{"broker"})
+
+	// MQTTConnected is 1 while the broker is connected to MQTT.
+	MQTTConnected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mqtt_connected",
+		Help:      "Whether the broker is currently connected to MQTT.",
+	}, []string{"broker"})
+
+	// MQTTConnects counts successful (re-)connections to MQTT.
+	MQTTConnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mqtt_connects_total",
+		Help:      "Number of successful connections to MQTT, including reconnects.",
+	}, []string{"broker"})
+
+	// MQTTConnectionsLost counts lost MQTT connections.
+	MQTTConnectionsLost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mqtt_connections_lost_total",
+		Help:      "Number of lost connections to MQTT.",
+	}, []string{"broker"})
+
+	// ActiveStreams tracks the number of active Connect streams per method.
+	ActiveStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_streams",
+		Help:      "Number of active Connect streams.",
+	}, []string{"method"})
+
+	// AutomationExecutions counts executions of automation handlers.
+	AutomationExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "automation_executions_total",
+		Help:      "Number of automation handler executions.",
+	}, []string{"bundle", "kind"})
+
+	// AutomationErrors counts automation handler executions that failed.
+	AutomationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "automation_errors_total",
+		Help:      "Number of automation handler executions that failed.",
+	}, []string{"bundle", "kind"})
+
+	// AutomationTimeouts counts automation handler executions that have been
+	// interrupted because they exceeded their timeout.
+	AutomationTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "automation_timeouts_total",
+		Help:      "Number of automation handler executions that timed out.",
+	}, []string{"bundle", "kind"})
+
+	// AutomationDuration observes the duration of automation handler
+	// executions.
+	AutomationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "automation_duration_seconds",
+		Help:      "Duration of automation handler executions.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"bundle", "kind"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		EventsPublished,
+		EventsReceived,
+		EventsDelivered,
+		EventsDropped,
+		QueueFill,
+		MQTTConnected,
+		MQTTConnects,
+		MQTTConnectionsLost,
+		ActiveStreams,
+		AutomationExecutions,
+		AutomationErrors,
+		AutomationTimeouts,
+		AutomationDuration,
+	)
+}
+
+// TrackStream increments the active streams gauge for method and returns a
+// function that decrements it again.
+func TrackStream(method string) func() {
+	gauge := ActiveStreams.WithLabelValues(method)
+	gauge.Inc()
+
+	return gauge.Dec
+}
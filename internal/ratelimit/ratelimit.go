@@ -0,0 +1,301 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"golang.org/x/time/rate"
+)
+
+// ErrLimitExceeded is returned if a rate limit or quota has been exceeded.
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// idleTimeout is the duration after which unused token buckets are removed.
+const idleTimeout = 10 * time.Minute
+
+// Scope describes what a token bucket is keyed by.
+type Scope string
+
+const (
+	ScopeUser Scope = "user"
+	ScopePeer Scope = "peer"
+	ScopeType Scope = "type"
+)
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	rejected int
+}
+
+type buckets struct {
+	limit rate.Limit
+	burst int
+
+	entries map[string]*bucket
+}
+
+func newBuckets(limit float64, burst int) *buckets {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &buckets{
+		limit:   rate.Limit(limit),
+		burst:   burst,
+		entries: make(map[string]*bucket),
+	}
+}
+
+// reserve reserves one token from the bucket of key. It returns false if the
+// bucket is exhausted. The returned reservation is nil if the bucket is not
+// limited.
+func (b *buckets) reserve(key string, now time.Time) (*rate.Reservation, bool) {
+	if b.limit <= 0 {
+		return nil, true
+	}
+
+	e, ok := b.entries[key]
+	if !ok {
+		e = &bucket{limiter: rate.NewLimiter(b.limit, b.burst)}
+		b.entries[key] = e
+	}
+
+	e.lastSeen = now
+
+	r := e.limiter.ReserveN(now, 1)
+	if !r.OK() || r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		e.rejected++
+
+		return nil, false
+	}
+
+	return r, true
+}
+
+// Limiter enforces token-bucket limits on published events and quotas on
+// the number of concurrent subscriptions. A nil *Limiter allows everything.
+type Limiter struct {
+	cfg config.RateLimits
+	log *slog.Logger
+
+	lock          sync.Mutex
+	buckets       map[Scope]*buckets
+	subscriptions map[string]int
+}
+
+// New returns a new limiter for cfg.
+func New(cfg config.RateLimits) *Limiter {
+	return &Limiter{
+		cfg: cfg,
+		log: slog.Default().With("subsystem", "ratelimit"),
+		buckets: map[Scope]*buckets{
+			ScopeUser: newBuckets(cfg.PublishPerUser, cfg.PublishPerUserBurst),
+			ScopePeer: newBuckets(cfg.PublishPerPeer, cfg.PublishPerPeerBurst),
+			ScopeType: newBuckets(cfg.PublishPerType, cfg.PublishPerTypeBurst),
+		},
+		subscriptions: make(map[string]int),
+	}
+}
+
+// Start periodically removes idle token buckets until ctx is cancelled.
+func (l *Limiter) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				l.cleanup(now)
+			}
+		}
+	}()
+}
+
+func (l *Limiter) cleanup(now time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, b := range l.buckets {
+		for key, e := range b.entries {
+			if now.Sub(e.lastSeen) > idleTimeout {
+				delete(b.entries, key)
+			}
+		}
+	}
+}
+
+// AllowPublish consumes one token from the buckets of user, peer and typeUrl
+// and returns an error wrapping ErrLimitExceeded if any of them is exhausted.
+// Tokens are only consumed if all buckets allow the publish. Empty keys are
+// not limited.
+func (l *Limiter) AllowPublish(user, peer, typeUrl string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+
+	var reservations []*rate.Reservation
+
+	for _, check := range []struct {
+		scope Scope
+		key   string
+	}{
+		{ScopeUser, user},
+		{ScopePeer, peerHost(peer)},
+		{ScopeType, typeUrl},
+	} {
+		if check.key == "" {
+			continue
+		}
+
+		r, ok := l.buckets[check.scope].reserve(check.key, now)
+		if !ok {
+			// give back the tokens of the buckets that allowed the publish
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+
+			l.log.Warn("publish rate limit exceeded", "scope", check.scope, "key", check.key)
+
+			return fmt.Errorf("%w: too many events published for %s %q", ErrLimitExceeded, check.scope, check.key)
+		}
+
+		if r != nil {
+			reservations = append(reservations, r)
+		}
+	}
+
+	return nil
+}
+
+// AcquireSubscription reserves a subscription slot for user. The returned
+// function must be called once the subscription ended. An anonymous user is
+// not limited.
+func (l *Limiter) AcquireSubscription(user string) (func(), error) {
+	if l == nil || user == "" || l.cfg.SubscriptionsPerUser <= 0 {
+		return func() {}, nil
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.subscriptions[user] >= l.cfg.SubscriptionsPerUser {
+		return nil, fmt.Errorf("%w: too many concurrent subscriptions for user %q", ErrLimitExceeded, user)
+	}
+
+	l.subscriptions[user]++
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+
+			l.subscriptions[user]--
+			if l.subscriptions[user] <= 0 {
+				delete(l.subscriptions, user)
+			}
+		})
+	}, nil
+}
+
+// BucketUsage describes the current state of a token bucket.
+type BucketUsage struct {
+	Scope    Scope     `json:"scope"`
+	Key      string    `json:"key"`
+	Tokens   float64   `json:"tokens"`
+	Burst    int       `json:"burst"`
+	Rejected int       `json:"rejected"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Usage describes the current usage of all limits.
+type Usage struct {
+	Limits        config.RateLimits `json:"limits"`
+	Publish       []BucketUsage     `json:"publish"`
+	Subscriptions map[string]int    `json:"subscriptions"`
+}
+
+// Usage returns the current usage of all limits.
+func (l *Limiter) Usage() Usage {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+
+	usage := Usage{
+		Limits:        l.cfg,
+		Publish:       []BucketUsage{},
+		Subscriptions: make(map[string]int, len(l.subscriptions)),
+	}
+
+	for scope, b := range l.buckets {
+		for key, e := range b.entries {
+			usage.Publish = append(usage.Publish, BucketUsage{
+				Scope:    scope,
+				Key:      key,
+				Tokens:   e.limiter.TokensAt(now),
+				Burst:    b.burst,
+				Rejected: e.rejected,
+				LastSeen: e.lastSeen,
+			})
+		}
+	}
+
+	sort.Slice(usage.Publish, func(i, j int) bool {
+		if usage.Publish[i].Scope != usage.Publish[j].Scope {
+			return usage.Publish[i].Scope < usage.Publish[j].Scope
+		}
+
+		return usage.Publish[i].Key < usage.Publish[j].Key
+	})
+
+	for user, count := range l.subscriptions {
+		usage.Subscriptions[user] = count
+	}
+
+	return usage
+}
+
+// ServeHTTP serves the current usage as JSON.
+func (l *Limiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(l.Usage()); err != nil {
+		l.log.Error("failed to encode rate limit usage", "error", err)
+	}
+}
+
+// peerHost strips the port from a peer address so all connections of a
+// host share the same bucket.
+func peerHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
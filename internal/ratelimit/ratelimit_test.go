@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+)
+
+func TestAllowPublish(t *testing.T) {
+	l := New(config.RateLimits{
+		PublishPerUser:      1,
+		PublishPerUserBurst: 2,
+		PublishPerPeer:      1,
+		PublishPerPeerBurst: 3,
+	})
+
+	// the user bucket allows a burst of two events
+	require.NoError(t, l.AllowPublish("alice", "10.0.0.1:1234", "tkd.events.v1.Event"))
+	require.NoError(t, l.AllowPublish("alice", "10.0.0.1:1235", "tkd.events.v1.Event"))
+	require.ErrorIs(t, l.AllowPublish("alice", "10.0.0.1:1236", "tkd.events.v1.Event"), ErrLimitExceeded)
+
+	// other users share the bucket of the peer host
+	require.NoError(t, l.AllowPublish("bob", "10.0.0.1:4321", "tkd.events.v1.Event"))
+	require.ErrorIs(t, l.AllowPublish("bob", "10.0.0.1:4321", "tkd.events.v1.Event"), ErrLimitExceeded)
+
+	// the per-type limit is disabled
+	require.NoError(t, l.AllowPublish("", "10.0.0.2:1", "tkd.events.v1.Event"))
+
+	usage := l.Usage()
+	require.Len(t, usage.Publish, 4)
+	require.Equal(t, ScopePeer, usage.Publish[0].Scope)
+	require.Equal(t, "10.0.0.1", usage.Publish[0].Key)
+	require.Equal(t, 1, usage.Publish[0].Rejected)
+
+	// idle buckets are removed
+	l.cleanup(time.Now().Add(2 * idleTimeout))
+	require.Empty(t, l.Usage().Publish)
+}
+
+func TestRejectedPublishKeepsTokens(t *testing.T) {
+	l := New(config.RateLimits{
+		PublishPerUser:      0.001,
+		PublishPerUserBurst: 5,
+		PublishPerType:      0.001,
+		PublishPerTypeBurst: 1,
+	})
+
+	require.NoError(t, l.AllowPublish("alice", "", "tkd.events.v1.Event"))
+
+	// rejected by the type bucket
+	for i := 0; i < 3; i++ {
+		require.ErrorIs(t, l.AllowPublish("alice", "", "tkd.events.v1.Event"), ErrLimitExceeded)
+	}
+
+	// the user bucket has only been charged for the accepted publish
+	usage := l.Usage()
+	require.Len(t, usage.Publish, 2)
+	require.Equal(t, ScopeUser, usage.Publish[1].Scope)
+	require.InDelta(t, 4, usage.Publish[1].Tokens, 0.01)
+	require.Equal(t, ScopeType, usage.Publish[0].Scope)
+	require.Equal(t, 3, usage.Publish[0].Rejected)
+}
+
+func TestAcquireSubscription(t *testing.T) {
+	l := New(config.RateLimits{SubscriptionsPerUser: 1})
+
+	release, err := l.AcquireSubscription("alice")
+	require.NoError(t, err)
+
+	_, err = l.AcquireSubscription("alice")
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	// anonymous subscriptions are not limited
+	_, err = l.AcquireSubscription("")
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]int{"alice": 1}, l.Usage().Subscriptions)
+
+	release()
+	release()
+
+	release, err = l.AcquireSubscription("alice")
+	require.NoError(t, err)
+	release()
+
+	// a nil limiter allows everything
+	var nilLimiter *Limiter
+	require.NoError(t, nilLimiter.AllowPublish("alice", "", ""))
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation/bundle"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AutomationService struct {
+	eventsservicev1connect.UnimplementedAutomationServiceHandler
+
+	bundles *bundle.Manager
+}
+
+func NewAutomationService(bundles *bundle.Manager) *AutomationService {
+	return &AutomationService{bundles: bundles}
+}
+
+func (svc *AutomationService) ListBundles(ctx context.Context, req *connect.Request[eventsservicev1.ListBundlesRequest]) (*connect.Response[eventsservicev1.ListBundlesResponse], error) {
+	res := new(eventsservicev1.ListBundlesResponse)
+
+	for _, info := range svc.bundles.List() {
+		pb, err := bundleToProto(info)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Bundles = append(res.Bundles, pb)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *AutomationService) UploadBundle(ctx context.Context, req *connect.Request[eventsservicev1.UploadBundleRequest]) (*connect.Response[eventsservicev1.UploadBundleResponse], error) {
+	info, err := svc.bundles.Upload(req.Msg.Name, req.Msg.Content, req.Msg.Overwrite)
+	if err != nil {
+		return nil, bundleError(err)
+	}
+
+	pb, err := bundleToProto(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.UploadBundleResponse{Bundle: pb}), nil
+}
+
+func (svc *AutomationService) EnableBundle(ctx context.Context, req *connect.Request[eventsservicev1.EnableBundleRequest]) (*connect.Response[eventsservicev1.EnableBundleResponse], error) {
+	info, err := svc.bundles.Enable(req.Msg.Name)
+	if err != nil {
+		return nil, bundleError(err)
+	}
+
+	pb, err := bundleToProto(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.EnableBundleResponse{Bundle: pb}), nil
+}
+
+func (svc *AutomationService) DisableBundle(ctx context.Context, req *connect.Request[eventsservicev1.DisableBundleRequest]) (*connect.Response[eventsservicev1.DisableBundleResponse], error) {
+	info, err := svc.bundles.Disable(req.Msg.Name)
+	if err != nil {
+		return nil, bundleError(err)
+	}
+
+	pb, err := bundleToProto(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.DisableBundleResponse{Bundle: pb}), nil
+}
+
+func (svc *AutomationService) DeleteBundle(ctx context.Context, req *connect.Request[eventsservicev1.DeleteBundleRequest]) (*connect.Response[eventsservicev1.DeleteBundleResponse], error) {
+	if err := svc.bundles.Delete(req.Msg.Name); err != nil {
+		return nil, bundleError(err)
+	}
+
+	return connect.NewResponse(new(eventsservicev1.DeleteBundleResponse)), nil
+}
+
+func (svc *AutomationService) UpdateBundleParameters(ctx context.Context, req *connect.Request[eventsservicev1.UpdateBundleParametersRequest]) (*connect.Response[eventsservicev1.UpdateBundleParametersResponse], error) {
+	info, err := svc.bundles.SetParameters(req.Msg.Name, req.Msg.Values.AsMap())
+	if err != nil {
+		return nil, bundleError(err)
+	}
+
+	pb, err := bundleToProto(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.UpdateBundleParametersResponse{Bundle: pb}), nil
+}
+
+func (svc *AutomationService) ListBundleLogs(ctx context.Context, req *connect.Request[eventsservicev1.ListBundleLogsRequest]) (*connect.Response[eventsservicev1.ListBundleLogsResponse], error) {
+	buffer, err := svc.bundles.Logs(req.Msg.Name)
+	if err != nil {
+		return nil, bundleError(err)
+	}
+
+	var since time.Time
+	if req.Msg.Since != nil {
+		since = req.Msg.Since.AsTime()
+	}
+
+	logs := buffer.Read(logLevel(req.Msg.MinLevel), since)
+	if limit := int(req.Msg.Limit); limit > 0 && len(logs) > limit {
+		logs = logs[len(logs)-limit:]
+	}
+
+	res := &eventsservicev1.ListBundleLogsResponse{
+		Logs: make([]*eventsservicev1.BundleLog, len(logs)),
+	}
+
+	for idx, l := range logs {
+		res.Logs[idx] = logToProto(l)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *AutomationService) TailBundleLogs(ctx context.Context, req *connect.Request[eventsservicev1.TailBundleLogsRequest], stream *connect.ServerStream[eventsservicev1.TailBundleLogsResponse]) error {
+	buffer, err := svc.bundles.Logs(req.Msg.Name)
+	if err != nil {
+		return bundleError(err)
+	}
+
+	minLevel := logLevel(req.Msg.MinLevel)
+
+	// start watching before reading the backlog so no message is lost in
+	// between. Messages logged in the meantime are skipped if they are
+	// already part of the backlog.
+	logs, stop := buffer.Watch(minLevel)
+	defer stop()
+
+	var last time.Time
+	if backlog := int(req.Msg.Backlog); backlog > 0 {
+		buffered := buffer.Read(minLevel, time.Time{})
+		if len(buffered) > backlog {
+			buffered = buffered[len(buffered)-backlog:]
+		}
+
+		for _, l := range buffered {
+			if err := stream.Send(&eventsservicev1.TailBundleLogsResponse{Log: logToProto(l)}); err != nil {
+				return err
+			}
+
+			last = l.Time
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case l, ok := <-logs:
+			if !ok {
+				return nil
+			}
+
+			if !l.Time.After(last) {
+				continue
+			}
+
+			if err := stream.Send(&eventsservicev1.TailBundleLogsResponse{Log: logToProto(l)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func logToProto(l bundle.Log) *eventsservicev1.BundleLog {
+	pb := &eventsservicev1.BundleLog{
+		Time:    timestamppb.New(l.Time),
+		Message: l.Message,
+	}
+
+	switch {
+	case l.Level >= slog.LevelError:
+		pb.Level = eventsservicev1.LogLevel_LOG_LEVEL_ERROR
+	case l.Level >= slog.LevelWarn:
+		pb.Level = eventsservicev1.LogLevel_LOG_LEVEL_WARN
+	case l.Level >= slog.LevelInfo:
+		pb.Level = eventsservicev1.LogLevel_LOG_LEVEL_INFO
+	default:
+		pb.Level = eventsservicev1.LogLevel_LOG_LEVEL_DEBUG
+	}
+
+	return pb
+}
+
+// logLevel returns the slog level for lvl. LOG_LEVEL_UNSPECIFIED includes
+// all messages.
+func logLevel(lvl eventsservicev1.LogLevel) slog.Level {
+	switch lvl {
+	case eventsservicev1.LogLevel_LOG_LEVEL_INFO:
+		return slog.LevelInfo
+	case eventsservicev1.LogLevel_LOG_LEVEL_WARN:
+		return slog.LevelWarn
+	case eventsservicev1.LogLevel_LOG_LEVEL_ERROR:
+		return slog.LevelError
+	}
+
+	return slog.LevelDebug
+}
+
+func bundleToProto(info bundle.Info) (*eventsservicev1.AutomationBundle, error) {
+	pb := &eventsservicev1.AutomationBundle{
+		Name:   info.Name,
+		Source: info.Source,
+	}
+
+	switch info.State {
+	case bundle.StateRunning:
+		pb.State = eventsservicev1.BundleState_BUNDLE_STATE_RUNNING
+	case bundle.StateDisabled:
+		pb.State = eventsservicev1.BundleState_BUNDLE_STATE_DISABLED
+	case bundle.StateFailed:
+		pb.State = eventsservicev1.BundleState_BUNDLE_STATE_FAILED
+	}
+
+	if info.Err != nil {
+		pb.Error = info.Err.Error()
+	}
+
+	if b := info.Bundle; b != nil {
+		pb.Version = b.Version
+		pb.License = b.License
+
+		if len(b.AutomationConfig.Parameters) > 0 {
+			params, err := structpb.NewStruct(b.AutomationConfig.Parameters)
+			if err != nil {
+				return nil, err
+			}
+
+			pb.Parameters = params
+		}
+
+		values, err := b.Values()
+		if err != nil {
+			pb.Error = err.Error()
+		} else if len(values) > 0 {
+			pb.Values, err = structpb.NewStruct(values)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pb, nil
+}
+
+func bundleError(err error) error {
+	switch {
+	case errors.Is(err, bundle.ErrBundleNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+
+	case errors.Is(err, bundle.ErrBundleExists):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+
+	case errors.Is(err, bundle.ErrInvalidName), errors.Is(err, bundle.ErrInvalidBundle), errors.Is(err, bundle.ErrUnknownParameter):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+
+	case errors.Is(err, bundle.ErrBundleNotLoaded):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+
+	return err
+}
+
+var _ eventsservicev1connect.AutomationServiceHandler = (*AutomationService)(nil)
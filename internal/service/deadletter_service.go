@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	connect "github.com/bufbuild/connect-go"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/automation"
+	"github.com/tierklinik-dobersberg/events-service/internal/deadletter"
+)
+
+// EngineLookup returns the automation engine of the given bundle or nil if
+// there's no such bundle.
+type EngineLookup func(bundle string) *automation.Engine
+
+type DeadLetterService struct {
+	eventsservicev1connect.UnimplementedDeadLetterServiceHandler
+
+	store   *deadletter.Store
+	engines EngineLookup
+}
+
+func NewDeadLetterService(store *deadletter.Store, engines EngineLookup) *DeadLetterService {
+	return &DeadLetterService{
+		store:   store,
+		engines: engines,
+	}
+}
+
+func (svc *DeadLetterService) ListDeadLetters(ctx context.Context, req *connect.Request[eventsservicev1.ListDeadLettersRequest]) (*connect.Response[eventsservicev1.ListDeadLettersResponse], error) {
+	letters, err := svc.store.List(req.Msg.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.ListDeadLettersResponse{
+		DeadLetters: letters,
+	}), nil
+}
+
+func (svc *DeadLetterService) RedriveDeadLetter(ctx context.Context, req *connect.Request[eventsservicev1.RedriveDeadLetterRequest]) (*connect.Response[eventsservicev1.RedriveDeadLetterResponse], error) {
+	letter, err := svc.store.Get(req.Msg.Id)
+	if err != nil {
+		return nil, deadLetterError(err)
+	}
+
+	failed, err := svc.redrive(letter)
+	if err != nil {
+		return nil, deadLetterError(err)
+	}
+
+	return connect.NewResponse(&eventsservicev1.RedriveDeadLetterResponse{
+		DeadLetter: failed,
+	}), nil
+}
+
+func (svc *DeadLetterService) RedriveAllDeadLetters(ctx context.Context, req *connect.Request[eventsservicev1.RedriveAllDeadLettersRequest]) (*connect.Response[eventsservicev1.RedriveAllDeadLettersResponse], error) {
+	letters, err := svc.store.List(req.Msg.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(eventsservicev1.RedriveAllDeadLettersResponse)
+
+	for _, letter := range letters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		failed, err := svc.redrive(letter)
+		if err != nil {
+			// letters that cannot be re-driven at the moment are kept and
+			// reported so the remaining letters are still re-driven.
+			if isRedrivePrecondition(err) {
+				if res.Skipped == nil {
+					res.Skipped = make(map[string]string)
+				}
+
+				res.Skipped[letter.Id] = err.Error()
+
+				continue
+			}
+
+			return nil, deadLetterError(err)
+		}
+
+		if failed != nil {
+			res.Failed = append(res.Failed, failed)
+		} else {
+			res.Succeeded++
+		}
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *DeadLetterService) DeleteDeadLetter(ctx context.Context, req *connect.Request[eventsservicev1.DeleteDeadLetterRequest]) (*connect.Response[eventsservicev1.DeleteDeadLetterResponse], error) {
+	if err := svc.store.Delete(req.Msg.Id); err != nil {
+		return nil, deadLetterError(err)
+	}
+
+	return connect.NewResponse(&eventsservicev1.DeleteDeadLetterResponse{}), nil
+}
+
+// redrive delivers letter to the failed handler again. On success, the dead
+// letter is removed. Otherwise the updated dead letter is returned.
+func (svc *DeadLetterService) redrive(letter *eventsservicev1.DeadLetter) (*eventsservicev1.DeadLetter, error) {
+	engine := svc.engines(letter.Bundle)
+	if engine == nil {
+		return nil, fmt.Errorf("%w: %q", errBundleNotFound, letter.Bundle)
+	}
+
+	err := engine.Redeliver(letter.HandlerId, &eventsv1.Event{
+		Event:    letter.Event,
+		Retained: letter.Retained,
+	})
+
+	if err != nil {
+		if isRedrivePrecondition(err) {
+			return nil, err
+		}
+
+		return svc.store.RecordFailure(letter.Id, err)
+	}
+
+	return nil, svc.store.Delete(letter.Id)
+}
+
+var errBundleNotFound = errors.New("automation bundle not found")
+
+// isRedrivePrecondition reports whether err means that a dead letter cannot
+// be re-driven because its bundle or handler is not available.
+func isRedrivePrecondition(err error) bool {
+	return errors.Is(err, errBundleNotFound) ||
+		errors.Is(err, automation.ErrNoHandler) ||
+		errors.Is(err, automation.ErrEngineStopped)
+}
+
+func deadLetterError(err error) error {
+	switch {
+	case errors.Is(err, deadletter.ErrNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+
+	case isRedrivePrecondition(err):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+
+	return err
+}
+
+var _ eventsservicev1connect.DeadLetterServiceHandler = (*DeadLetterService)(nil)
@@ -2,61 +2,201 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	connect "github.com/bufbuild/connect-go"
 	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1/eventsv1connect"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
 	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"github.com/tierklinik-dobersberg/events-service/internal/httpauth"
+	"github.com/tierklinik-dobersberg/events-service/internal/metrics"
+	"github.com/tierklinik-dobersberg/events-service/internal/ratelimit"
+	"github.com/tierklinik-dobersberg/events-service/internal/tracing"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type EventsService struct {
 	eventsv1connect.UnimplementedEventServiceHandler
 
-	broker *broker.Broker
-	l      *slog.Logger
+	broker  *broker.Broker
+	limiter *ratelimit.Limiter
+	auth    *httpauth.Authenticator
+	l       *slog.Logger
 }
 
-type fakeBidiStream struct {
-	*connect.ServerStream[eventsv1.Event]
-	*connect.Request[eventsv1.SubscribeOnceRequest]
+const (
+	// OnceCountHeader may be set on SubscribeOnce requests to specify the
+	// number of events to wait for per requested type. Defaults to 1.
+	OnceCountHeader = "X-Events-Once-Count"
 
-	iter int
-}
-
-func (fb *fakeBidiStream) Receive() (*eventsv1.SubscribeRequest, error) {
-	if fb.iter >= len(fb.Msg.TypeUrls) {
-		return nil, io.EOF
-	}
+	// OnceTimeoutHeader may be set on SubscribeOnce requests to complete
+	// the stream successfully after the given duration (e.g. "30s"), even
+	// if not all events have been received.
+	OnceTimeoutHeader = "X-Events-Once-Timeout"
+)
 
-	val := fb.Request.Msg.TypeUrls[fb.iter]
+// NewEventsService returns a new EventsService. limiter may be nil to disable
+// rate limiting. authenticator is used to determine the user of streaming
+// requests, which are not handled by the auth interceptor, and may be nil
+// if no auth proxy is configured.
+func NewEventsService(broker *broker.Broker, limiter *ratelimit.Limiter, authenticator *httpauth.Authenticator) (*EventsService, error) {
+	return &EventsService{broker: broker, limiter: limiter, auth: authenticator, l: slog.Default().WithGroup("service")}, nil
+}
 
-	fb.iter++
+func (svc *EventsService) Subscribe(ctx context.Context, stream *connect.BidiStream[eventsv1.SubscribeRequest, eventsv1.Event]) error {
+	defer metrics.TrackStream("Subscribe")()
 
-	return &eventsv1.SubscribeRequest{
-		Kind: &eventsv1.SubscribeRequest_Subscribe{
-			Subscribe: val,
-		},
-	}, nil
-}
+	user, err := svc.remoteUserID(ctx, stream.RequestHeader())
+	if err != nil {
+		return err
+	}
 
-func NewEventsService(broker *broker.Broker) (*EventsService, error) {
-	return &EventsService{broker: broker, l: slog.Default().WithGroup("service")}, nil
-}
+	release, err := svc.limiter.AcquireSubscription(user)
+	if err != nil {
+		return limitError(err)
+	}
+	defer release()
 
-func (svc *EventsService) Subscribe(ctx context.Context, stream *connect.BidiStream[eventsv1.SubscribeRequest, eventsv1.Event]) error {
-	subscriber := broker.NewSubscriber(stream, svc.broker)
+	subscriber := broker.NewSubscriber(stream, svc.broker).WithClientInfo("subscribe", user)
 	return subscriber.Handle(ctx)
 }
 
 func (svc *EventsService) SubscribeOnce(ctx context.Context, req *connect.Request[eventsv1.SubscribeOnceRequest], stream *connect.ServerStream[eventsv1.Event]) error {
-	subscriber := broker.NewSubscriber(&fakeBidiStream{stream, req, 0}, svc.broker)
-	return subscriber.Handle(ctx)
+	if len(req.Msg.TypeUrls) == 0 {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing type_urls field"))
+	}
+
+	count := 1
+	if value := req.Header().Get(OnceCountHeader); value != "" {
+		var err error
+
+		count, err = strconv.Atoi(value)
+		if err != nil || count <= 0 {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid value for %s: %q", OnceCountHeader, value))
+		}
+	}
+
+	if value := req.Header().Get(OnceTimeoutHeader); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid value for %s: %q", OnceTimeoutHeader, value))
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errOnceTimeout)
+		defer cancel()
+	}
+
+	defer metrics.TrackStream("SubscribeOnce")()
+
+	user, err := svc.remoteUserID(ctx, req.Header())
+	if err != nil {
+		return err
+	}
+
+	release, err := svc.limiter.AcquireSubscription(user)
+	if err != nil {
+		return limitError(err)
+	}
+	defer release()
+
+	msgs := make(chan *eventsv1.Event, 100)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	_, unregister := svc.broker.RegisterClient(msgs, broker.ClientInfo{
+		Kind: "subscribe-once",
+		Peer: req.Peer().Addr,
+		User: user,
+	}, func() {
+		cancel(broker.ErrDisconnected)
+	})
+	defer unregister()
+
+	// remaining holds the number of events still expected per type
+	remaining := make(map[string]int, len(req.Msg.TypeUrls))
+	typeUrls := make([]string, 0, len(req.Msg.TypeUrls))
+
+	for _, typeUrl := range req.Msg.TypeUrls {
+		typeUrl = strings.TrimPrefix(typeUrl, "type.googleapis.com/")
+
+		if _, ok := remaining[typeUrl]; ok {
+			continue
+		}
+
+		remaining[typeUrl] = count
+		typeUrls = append(typeUrls, typeUrl)
+	}
+
+	// Subscribe blocks while sending retained events to msgs so it must
+	// not be called on the goroutine that drains msgs.
+	subscribed := make(chan struct{})
+	go func() {
+		defer close(subscribed)
+
+		for _, typeUrl := range typeUrls {
+			svc.broker.Subscribe(typeUrl, msgs)
+		}
+	}()
+
+	defer func() {
+		// keep draining until all retained events have been sent
+		for {
+			select {
+			case <-subscribed:
+				svc.broker.UnsubscribeAll(msgs)
+				return
+			case <-msgs:
+			}
+		}
+	}()
+
+	for len(remaining) > 0 {
+		select {
+		case <-ctx.Done():
+			switch cause := context.Cause(ctx); {
+			case errors.Is(cause, errOnceTimeout):
+				return nil
+			case errors.Is(cause, broker.ErrDisconnected):
+				return connect.NewError(connect.CodeAborted, cause)
+			}
+
+			return ctx.Err()
+
+		case msg := <-msgs:
+			typeUrl := strings.TrimPrefix(msg.Event.GetTypeUrl(), "type.googleapis.com/")
+			if _, ok := remaining[typeUrl]; !ok {
+				typeUrl = broker.Wildcard
+			}
+
+			if _, ok := remaining[typeUrl]; !ok {
+				continue
+			}
+
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+
+			remaining[typeUrl]--
+			if remaining[typeUrl] <= 0 {
+				delete(remaining, typeUrl)
+			}
+		}
+	}
+
+	return nil
 }
 
+var errOnceTimeout = errors.New("subscribe-once timeout")
+
 func (svc *EventsService) Publish(ctx context.Context, req *connect.Request[eventsv1.Event]) (*connect.Response[emptypb.Empty], error) {
 	if req.Msg.Event == nil {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid request message, missing event field"))
@@ -68,7 +208,21 @@ func (svc *EventsService) Publish(ctx context.Context, req *connect.Request[even
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing event field"))
 	}
 
-	if err := svc.broker.Publish(req.Msg); err != nil {
+	user, err := svc.remoteUserID(ctx, req.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.limiter.AllowPublish(user, req.Peer().Addr, req.Msg.Event.TypeUrl); err != nil {
+		return nil, limitError(err)
+	}
+
+	_, span := tracing.StartPublish(tracing.ExtractHeader(ctx, req.Header()), req.Msg)
+
+	err = svc.broker.Publish(req.Msg)
+	tracing.End(span, err)
+
+	if err != nil {
 		return nil, err
 	}
 
@@ -76,6 +230,15 @@ func (svc *EventsService) Publish(ctx context.Context, req *connect.Request[even
 }
 
 func (svc *EventsService) PublishStream(ctx context.Context, stream *connect.ClientStream[eventsv1.Event]) (*connect.Response[emptypb.Empty], error) {
+	defer metrics.TrackStream("PublishStream")()
+
+	user, err := svc.remoteUserID(ctx, stream.RequestHeader())
+	if err != nil {
+		return nil, err
+	}
+
+	parent := tracing.ExtractHeader(ctx, stream.RequestHeader())
+
 	for stream.Receive() {
 		if err := stream.Err(); err != nil {
 			return nil, err
@@ -85,7 +248,16 @@ func (svc *EventsService) PublishStream(ctx context.Context, stream *connect.Cli
 			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing event field"))
 		}
 
-		if err := svc.broker.Publish(stream.Msg()); err != nil {
+		if err := svc.limiter.AllowPublish(user, stream.Peer().Addr, stream.Msg().Event.TypeUrl); err != nil {
+			return nil, limitError(err)
+		}
+
+		_, span := tracing.StartPublish(parent, stream.Msg())
+
+		err := svc.broker.Publish(stream.Msg())
+		tracing.End(span, err)
+
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -97,4 +269,29 @@ func (svc *EventsService) PublishStream(ctx context.Context, stream *connect.Cli
 	return connect.NewResponse(new(emptypb.Empty)), nil
 }
 
+// remoteUserID returns the ID of the authenticated user. Since the auth
+// interceptor only handles unary requests, streams are authenticated using
+// svc.auth. The X-Remote-User-ID header is never used directly as it can
+// only be trusted if set by the auth proxy.
+func (svc *EventsService) remoteUserID(ctx context.Context, header http.Header) (string, error) {
+	if user := auth.From(ctx); user != nil && user.ID != "" {
+		return user.ID, nil
+	}
+
+	user, err := svc.auth.User(ctx, header)
+	if err != nil {
+		return "", err
+	}
+
+	return user.ID, nil
+}
+
+func limitError(err error) error {
+	if errors.Is(err, ratelimit.ErrLimitExceeded) {
+		return connect.NewError(connect.CodeResourceExhausted, err)
+	}
+
+	return err
+}
+
 var _ eventsv1connect.EventServiceHandler = (*EventsService)(nil)
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1/eventsv1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type fakeMessage struct {
+	mqtt.Message
+
+	topic    string
+	payload  []byte
+	retained bool
+}
+
+func (m *fakeMessage) Topic() string   { return m.topic }
+func (m *fakeMessage) Payload() []byte { return m.payload }
+func (m *fakeMessage) Retained() bool  { return m.retained }
+
+type fakeClient struct {
+	l        sync.Mutex
+	handlers map[string]mqtt.MessageHandler
+}
+
+func (c *fakeClient) Subscribe(topic string, _ byte, handler mqtt.MessageHandler) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.handlers[topic] = handler
+
+	return nil
+}
+
+func (c *fakeClient) Unsubscribe(topics ...string) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	for _, t := range topics {
+		delete(c.handlers, t)
+	}
+
+	return nil
+}
+
+func (c *fakeClient) Publish(string, byte, bool, []byte) error { return nil }
+
+func (c *fakeClient) handler(topic string) mqtt.MessageHandler {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.handlers[topic]
+}
+
+func setupEventsService(t *testing.T, opts ...broker.Option) (*fakeClient, *broker.Broker, eventsv1connect.EventServiceClient) {
+	t.Helper()
+
+	cli := &fakeClient{handlers: make(map[string]mqtt.MessageHandler)}
+
+	b, err := broker.NewBroker(context.Background(), cli, opts...)
+	require.NoError(t, err)
+
+	svc, err := NewEventsService(b, nil, nil)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle(eventsv1connect.NewEventServiceHandler(svc))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return cli, b, eventsv1connect.NewEventServiceClient(srv.Client(), srv.URL)
+}
+
+const stringTopic = "cis/protobuf/events/google.protobuf.StringValue"
+
+func stringMessage(t *testing.T, value string) mqtt.Message {
+	t.Helper()
+
+	evt, err := anypb.New(wrapperspb.String(value))
+	require.NoError(t, err)
+
+	payload, err := proto.Marshal(&eventsv1.Event{Event: evt})
+	require.NoError(t, err)
+
+	return &fakeMessage{topic: stringTopic, payload: payload}
+}
+
+func TestSubscribeOnce(t *testing.T) {
+	cli, _, client := setupEventsService(t)
+
+	req := connect.NewRequest(&eventsv1.SubscribeOnceRequest{
+		TypeUrls: []string{"google.protobuf.StringValue"},
+	})
+	req.Header().Set(OnceCountHeader, "2")
+
+	stream, err := client.SubscribeOnce(context.Background(), req)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.Eventually(t, func() bool {
+		return cli.handler(stringTopic) != nil
+	}, time.Second, time.Millisecond)
+
+	handler := cli.handler(stringTopic)
+	msgs := []mqtt.Message{
+		stringMessage(t, "1"),
+		stringMessage(t, "2"),
+		stringMessage(t, "3"),
+	}
+
+	go func() {
+		for _, msg := range msgs {
+			handler(nil, msg)
+		}
+	}()
+
+	var values []string
+	for stream.Receive() {
+		var msg wrapperspb.StringValue
+		require.NoError(t, stream.Msg().Event.UnmarshalTo(&msg))
+
+		values = append(values, msg.Value)
+	}
+
+	require.NoError(t, stream.Err())
+	require.Equal(t, []string{"1", "2"}, values)
+}
+
+func TestSubscribeOnceManyRetained(t *testing.T) {
+	cli, b, client := setupEventsService(t, broker.WithRetainedKeys(map[string]string{
+		"google.protobuf.StringValue": "value",
+	}, nil))
+
+	// keep the type subscribed so the broker caches the retained state of
+	// all entities.
+	msgs := make(chan *eventsv1.Event, 200)
+	b.Subscribe("google.protobuf.StringValue", msgs)
+	defer b.UnsubscribeAll(msgs)
+
+	require.Eventually(t, func() bool {
+		return cli.handler(stringTopic+"/#") != nil
+	}, time.Second, time.Millisecond)
+
+	// more retained events than fit into the subscription buffer
+	const count = 150
+
+	handler := cli.handler(stringTopic + "/#")
+	for i := 0; i < count; i++ {
+		msg := stringMessage(t, strconv.Itoa(i)).(*fakeMessage)
+		msg.topic += "/" + strconv.Itoa(i)
+		msg.retained = true
+
+		handler(nil, msg)
+	}
+
+	req := connect.NewRequest(&eventsv1.SubscribeOnceRequest{
+		TypeUrls: []string{"google.protobuf.StringValue"},
+	})
+	req.Header().Set(OnceCountHeader, strconv.Itoa(count))
+	req.Header().Set(OnceTimeoutHeader, "3s")
+
+	stream, err := client.SubscribeOnce(context.Background(), req)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var values []string
+	for stream.Receive() {
+		var msg wrapperspb.StringValue
+		require.NoError(t, stream.Msg().Event.UnmarshalTo(&msg))
+
+		values = append(values, msg.Value)
+	}
+
+	require.NoError(t, stream.Err())
+	require.Len(t, values, count)
+}
+
+func TestSubscribeOnceTimeout(t *testing.T) {
+	_, _, client := setupEventsService(t)
+
+	req := connect.NewRequest(&eventsv1.SubscribeOnceRequest{
+		TypeUrls: []string{"google.protobuf.StringValue"},
+	})
+	req.Header().Set(OnceTimeoutHeader, "50ms")
+
+	stream, err := client.SubscribeOnce(context.Background(), req)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.False(t, stream.Receive())
+	require.NoError(t, stream.Err())
+
+	// invalid headers are rejected
+	req.Header().Set(OnceCountHeader, "zero")
+
+	stream, err = client.SubscribeOnce(context.Background(), req)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	require.False(t, stream.Receive())
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(stream.Err()))
+}
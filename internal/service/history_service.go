@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	connect "github.com/bufbuild/connect-go"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/history"
+)
+
+// maxHistoryEvents is the maximum number of events returned by a single
+// history request.
+const maxHistoryEvents = 1000
+
+type HistoryService struct {
+	eventsservicev1connect.UnimplementedEventHistoryServiceHandler
+
+	store *history.Store
+}
+
+func NewHistoryService(store *history.Store) *HistoryService {
+	return &HistoryService{store: store}
+}
+
+func (svc *HistoryService) ListEvents(ctx context.Context, req *connect.Request[eventsservicev1.ListEventsRequest]) (*connect.Response[eventsservicev1.ListEventsResponse], error) {
+	if req.Msg.TypeUrl == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing type_url field"))
+	}
+
+	filter, err := history.FilterFromJSON(req.Msg.Filter)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	q := history.Query{
+		TypeURL: req.Msg.TypeUrl,
+		Limit:   int(req.Msg.Limit),
+		Filter:  filter,
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	q.Limit = min(q.Limit, maxHistoryEvents)
+
+	if req.Msg.From.IsValid() {
+		q.From = req.Msg.From.AsTime()
+	}
+
+	if req.Msg.To.IsValid() {
+		q.To = req.Msg.To.AsTime()
+	}
+
+	events, err := svc.store.List(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.ListEventsResponse{
+		Events: events,
+	}), nil
+}
+
+func (svc *HistoryService) GetLatestEvents(ctx context.Context, req *connect.Request[eventsservicev1.GetLatestEventsRequest]) (*connect.Response[eventsservicev1.GetLatestEventsResponse], error) {
+	if req.Msg.TypeUrl == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing type_url field"))
+	}
+
+	count := int(req.Msg.Count)
+	if count <= 0 {
+		count = 1
+	}
+
+	count = min(count, maxHistoryEvents)
+
+	events, err := svc.store.Latest(req.Msg.TypeUrl, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.GetLatestEventsResponse{
+		Events: events,
+	}), nil
+}
+
+var _ eventsservicev1connect.EventHistoryServiceHandler = (*HistoryService)(nil)
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	connect "github.com/bufbuild/connect-go"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type IntrospectionService struct {
+	eventsservicev1connect.UnimplementedIntrospectionServiceHandler
+
+	broker *broker.Broker
+}
+
+func NewIntrospectionService(broker *broker.Broker) *IntrospectionService {
+	return &IntrospectionService{broker: broker}
+}
+
+func (svc *IntrospectionService) ListTopics(ctx context.Context, req *connect.Request[eventsservicev1.ListTopicsRequest]) (*connect.Response[eventsservicev1.ListTopicsResponse], error) {
+	res := new(eventsservicev1.ListTopicsResponse)
+
+	for _, topic := range svc.broker.Topics() {
+		res.Topics = append(res.Topics, &eventsservicev1.Topic{
+			TypeUrl:         topic.TypeURL,
+			MqttTopic:       topic.MQTTTopic,
+			SubscriberCount: int32(topic.Subscribers),
+			RetainedCount:   int32(topic.Retained),
+		})
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *IntrospectionService) ListSubscribers(ctx context.Context, req *connect.Request[eventsservicev1.ListSubscribersRequest]) (*connect.Response[eventsservicev1.ListSubscribersResponse], error) {
+	res := new(eventsservicev1.ListSubscribersResponse)
+
+	for _, client := range svc.broker.Clients() {
+		if req.Msg.TypeUrl != "" && !slices.Contains(client.TypeURLs, req.Msg.TypeUrl) {
+			continue
+		}
+
+		res.Subscribers = append(res.Subscribers, &eventsservicev1.Subscriber{
+			Id:            client.ID,
+			Kind:          client.Kind,
+			Peer:          client.Peer,
+			User:          client.User,
+			ConnectTime:   timestamppb.New(client.ConnectTime),
+			TypeUrls:      client.TypeURLs,
+			QueueDepth:    int32(client.QueueDepth),
+			QueueCapacity: int32(client.QueueCapacity),
+		})
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *IntrospectionService) DisconnectSubscriber(ctx context.Context, req *connect.Request[eventsservicev1.DisconnectSubscriberRequest]) (*connect.Response[eventsservicev1.DisconnectSubscriberResponse], error) {
+	if err := svc.broker.Disconnect(req.Msg.Id); err != nil {
+		if errors.Is(err, broker.ErrUnknownClient) {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		}
+
+		return nil, err
+	}
+
+	return connect.NewResponse(new(eventsservicev1.DisconnectSubscriberResponse)), nil
+}
+
+var _ eventsservicev1connect.IntrospectionServiceHandler = (*IntrospectionService)(nil)
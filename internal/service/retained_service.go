@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	connect "github.com/bufbuild/connect-go"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+)
+
+// RetainedService implements both, the public RetainedService and the
+// RetainedAdminService.
+type RetainedService struct {
+	eventsservicev1connect.UnimplementedRetainedServiceHandler
+	eventsservicev1connect.UnimplementedRetainedAdminServiceHandler
+
+	broker    *broker.Broker
+	snapshots *broker.Broker
+}
+
+// NewRetainedService returns a new RetainedService. snapshots is used to
+// list the retained events of all types and must use a dedicated MQTT
+// connection since it temporarily subscribes to all topics. snapshots may be
+// nil in which case ListRetained is not available.
+func NewRetainedService(broker *broker.Broker, snapshots *broker.Broker) *RetainedService {
+	return &RetainedService{broker: broker, snapshots: snapshots}
+}
+
+func (svc *RetainedService) GetRetained(ctx context.Context, req *connect.Request[eventsservicev1.GetRetainedRequest]) (*connect.Response[eventsservicev1.GetRetainedResponse], error) {
+	if len(req.Msg.TypeUrls) == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing type_urls field"))
+	}
+
+	res := new(eventsservicev1.GetRetainedResponse)
+
+	for _, typeUrl := range req.Msg.TypeUrls {
+		events, err := svc.broker.Retained(ctx, typeUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Events = append(res.Events, retainedEventsToProto(events)...)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *RetainedService) ListRetained(ctx context.Context, req *connect.Request[eventsservicev1.ListRetainedRequest]) (*connect.Response[eventsservicev1.ListRetainedResponse], error) {
+	if svc.snapshots == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, fmt.Errorf("listing retained events requires the event history to be enabled"))
+	}
+
+	events, err := svc.snapshots.ListRetained(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.ListRetainedResponse{
+		Events: retainedEventsToProto(events),
+	}), nil
+}
+
+func (svc *RetainedService) ClearRetained(ctx context.Context, req *connect.Request[eventsservicev1.ClearRetainedRequest]) (*connect.Response[eventsservicev1.ClearRetainedResponse], error) {
+	if req.Msg.TypeUrl == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing type_url field"))
+	}
+
+	if err := svc.broker.ClearRetained(req.Msg.TypeUrl, req.Msg.Key); err != nil {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+
+	return connect.NewResponse(new(eventsservicev1.ClearRetainedResponse)), nil
+}
+
+func retainedEventsToProto(events []broker.RetainedEvent) []*eventsservicev1.RetainedEvent {
+	result := make([]*eventsservicev1.RetainedEvent, len(events))
+
+	for idx, evt := range events {
+		result[idx] = &eventsservicev1.RetainedEvent{
+			TypeUrl: evt.TypeURL,
+			Key:     evt.Key,
+			Event:   evt.Event.GetEvent(),
+		}
+	}
+
+	return result
+}
+
+var (
+	_ eventsservicev1connect.RetainedServiceHandler      = (*RetainedService)(nil)
+	_ eventsservicev1connect.RetainedAdminServiceHandler = (*RetainedService)(nil)
+)
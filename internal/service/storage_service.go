@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	connect "github.com/bufbuild/connect-go"
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	"github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1/eventsservicev1connect"
+	"github.com/tierklinik-dobersberg/events-service/internal/storage"
+)
+
+type StorageService struct {
+	eventsservicev1connect.UnimplementedStorageServiceHandler
+
+	store *storage.Store
+}
+
+func NewStorageService(store *storage.Store) *StorageService {
+	return &StorageService{store: store}
+}
+
+func (svc *StorageService) ListStorageNamespaces(ctx context.Context, req *connect.Request[eventsservicev1.ListStorageNamespacesRequest]) (*connect.Response[eventsservicev1.ListStorageNamespacesResponse], error) {
+	namespaces, err := svc.store.Namespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&eventsservicev1.ListStorageNamespacesResponse{
+		Namespaces: namespaces,
+	}), nil
+}
+
+func (svc *StorageService) ListStorageEntries(ctx context.Context, req *connect.Request[eventsservicev1.ListStorageEntriesRequest]) (*connect.Response[eventsservicev1.ListStorageEntriesResponse], error) {
+	if req.Msg.Bundle == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("missing bundle"))
+	}
+
+	entries, err := svc.store.List(storage.Namespace(req.Msg.Bundle), req.Msg.Prefix)
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	return connect.NewResponse(&eventsservicev1.ListStorageEntriesResponse{
+		Entries: entries,
+	}), nil
+}
+
+func (svc *StorageService) DeleteStorageEntry(ctx context.Context, req *connect.Request[eventsservicev1.DeleteStorageEntryRequest]) (*connect.Response[eventsservicev1.DeleteStorageEntryResponse], error) {
+	if req.Msg.Bundle == "" || req.Msg.Key == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("missing bundle or key"))
+	}
+
+	if err := svc.store.Delete(storage.Namespace(req.Msg.Bundle), req.Msg.Key); err != nil {
+		return nil, storageError(err)
+	}
+
+	return connect.NewResponse(new(eventsservicev1.DeleteStorageEntryResponse)), nil
+}
+
+func (svc *StorageService) WipeStorage(ctx context.Context, req *connect.Request[eventsservicev1.WipeStorageRequest]) (*connect.Response[eventsservicev1.WipeStorageResponse], error) {
+	if req.Msg.Bundle == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("missing bundle"))
+	}
+
+	count, err := svc.store.Wipe(storage.Namespace(req.Msg.Bundle))
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	return connect.NewResponse(&eventsservicev1.WipeStorageResponse{
+		Deleted: int64(count),
+	}), nil
+}
+
+func storageError(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return connect.NewError(connect.CodeNotFound, err)
+	}
+
+	return err
+}
+
+var _ eventsservicev1connect.StorageServiceHandler = (*StorageService)(nil)
@@ -0,0 +1,398 @@
+package sse
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"github.com/tierklinik-dobersberg/events-service/internal/codec"
+	"github.com/tierklinik-dobersberg/events-service/internal/httpauth"
+	"github.com/tierklinik-dobersberg/events-service/internal/ratelimit"
+)
+
+const (
+	// backlogSize is the number of events kept per type for
+	// Last-Event-ID resumption.
+	backlogSize = 100
+
+	// clientBufferSize is the number of events that may be queued for
+	// a client before it is disconnected.
+	clientBufferSize = 100
+
+	// maxTypes is the maximum number of types a client may subscribe to.
+	maxTypes = 50
+
+	keepAliveInterval = 30 * time.Second
+)
+
+type Broker interface {
+	Subscribe(string, chan *eventsv1.Event)
+	Unsubscribe(string, chan *eventsv1.Event)
+	RegisterClient(chan *eventsv1.Event, broker.ClientInfo, func()) (string, func())
+	RetainedKey(*eventsv1.Event) string
+}
+
+type entry struct {
+	id      uint64
+	typeUrl string
+	data    []byte
+}
+
+type topic struct {
+	backlog []entry
+	clients map[chan entry]struct{}
+
+	// retained holds the latest retained event per entity key, see
+	// broker.WithRetainedKeys. Types without a key use the empty key.
+	retained map[string]entry
+
+	// cancel stops the subscription at the broker
+	cancel context.CancelFunc
+	idle   *time.Timer
+}
+
+// Handler streams events to browsers using Server-Sent Events. Each type
+// is subscribed once at the broker and kept in a small backlog so
+// clients can resume using the Last-Event-ID header. Types without clients
+// are unsubscribed after Linger.
+type Handler struct {
+	ctx     context.Context
+	broker  Broker
+	auth    *httpauth.Authenticator
+	limiter *ratelimit.Limiter
+	codec   *codec.JSON
+	log     *slog.Logger
+
+	// Linger is the time a type is kept subscribed after the last client
+	// disconnected so clients can resume using the Last-Event-ID header.
+	Linger time.Duration
+
+	l      sync.Mutex
+	seq    uint64
+	topics map[string]*topic
+}
+
+// NewHandler returns a new SSE handler. Subscriptions at the broker
+// are kept until ctx is cancelled or the last client of a type is gone.
+// resolver is used to marshal events to JSON and may be nil. authenticator
+// and limiter may be nil to disable authentication and subscription quotas.
+func NewHandler(ctx context.Context, broker Broker, resolver codec.Resolver, authenticator *httpauth.Authenticator, limiter *ratelimit.Limiter) *Handler {
+	return &Handler{
+		ctx:     ctx,
+		broker:  broker,
+		auth:    authenticator,
+		limiter: limiter,
+		codec:   codec.NewCodec(resolver),
+		log:     slog.Default().With("subsystem", "sse"),
+		Linger:  time.Minute,
+		// Event IDs are based on the current time so IDs issued by a
+		// previous process do not replay unrelated events.
+		seq:    uint64(time.Now().UnixMicro()),
+		topics: make(map[string]*topic),
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var types []string
+	for _, value := range r.URL.Query()["type"] {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, strings.TrimPrefix(t, "type.googleapis.com/"))
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		http.Error(w, "missing type query parameter", http.StatusBadRequest)
+		return
+	}
+
+	slices.Sort(types)
+	types = slices.Compact(types)
+
+	if len(types) > maxTypes {
+		http.Error(w, fmt.Sprintf("too many types, at most %d are allowed", maxTypes), http.StatusBadRequest)
+		return
+	}
+
+	var lastEventID uint64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		var err error
+		lastEventID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID header", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := h.auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), httpauth.StatusCode(err))
+		return
+	}
+
+	release, err := h.limiter.AcquireSubscription(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id, unregister := h.broker.RegisterClient(nil, broker.ClientInfo{
+		Kind:     "sse",
+		Peer:     r.RemoteAddr,
+		User:     user.ID,
+		TypeURLs: types,
+	}, cancel)
+	defer unregister()
+
+	log := h.log.With("id", id, "peer", r.RemoteAddr, "types", types)
+	if user.ID != "" {
+		log = log.With("user", user.ID)
+	}
+
+	client := make(chan entry, clientBufferSize)
+	replay := h.register(client, types, lastEventID)
+	defer h.unregister(client, types)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	log.Debug("client connected", "lastEventId", lastEventID, "replay", len(replay))
+
+	for _, e := range replay {
+		if err := writeEntry(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug("client disconnected")
+			return
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case e, ok := <-client:
+			if !ok {
+				// the client could not keep up and has been dropped, it
+				// may reconnect using the Last-Event-ID header.
+				log.Warn("client too slow, closing stream")
+				return
+			}
+
+			if err := writeEntry(w, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEntry(w http.ResponseWriter, e entry) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.id, e.typeUrl, e.data)
+	return err
+}
+
+// register adds client as a receiver for types and returns all events that
+// should be replayed to the client. If lastEventID is zero, only the
+// retained events are replayed.
+func (h *Handler) register(client chan entry, types []string, lastEventID uint64) []entry {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	var replay []entry
+
+	for _, t := range types {
+		tp := h.getTopic(t)
+		tp.clients[client] = struct{}{}
+
+		if lastEventID == 0 {
+			for _, e := range tp.retained {
+				replay = append(replay, e)
+			}
+
+			continue
+		}
+
+		for _, e := range tp.backlog {
+			if e.id > lastEventID {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	// events may be spread over multiple types so make sure they are
+	// replayed in the order they were received.
+	slices.SortFunc(replay, func(a, b entry) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	return replay
+}
+
+func (h *Handler) unregister(client chan entry, types []string) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	for _, t := range types {
+		tp, ok := h.topics[t]
+		if !ok {
+			continue
+		}
+
+		// the client may already have been removed by dispatch
+		delete(tp.clients, client)
+
+		if len(tp.clients) == 0 && tp.idle == nil {
+			tp.idle = time.AfterFunc(h.Linger, func() {
+				h.release(t, tp)
+			})
+		}
+	}
+}
+
+// release unsubscribes tp at the broker unless a new client registered
+// in the meantime.
+func (h *Handler) release(typeUrl string, tp *topic) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	tp.idle = nil
+
+	if len(tp.clients) > 0 || h.topics[typeUrl] != tp {
+		return
+	}
+
+	delete(h.topics, typeUrl)
+	tp.cancel()
+
+	h.log.Debug("unsubscribed idle type", "typeUrl", typeUrl)
+}
+
+// getTopic returns the topic for typeUrl and subscribes at the broker if
+// required. h.l must be held.
+func (h *Handler) getTopic(typeUrl string) *topic {
+	if tp, ok := h.topics[typeUrl]; ok {
+		if tp.idle != nil {
+			tp.idle.Stop()
+			tp.idle = nil
+		}
+
+		return tp
+	}
+
+	ctx, cancel := context.WithCancel(h.ctx)
+
+	tp := &topic{
+		clients:  make(map[chan entry]struct{}),
+		retained: make(map[string]entry),
+		cancel:   cancel,
+	}
+	h.topics[typeUrl] = tp
+
+	msgs := make(chan *eventsv1.Event, 100)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-msgs:
+				h.dispatch(tp, typeUrl, msg)
+			}
+		}
+	}()
+
+	// Subscribe may immediately send a retained message to msgs so it must
+	// not be called with h.l held.
+	go func() {
+		h.broker.Subscribe(typeUrl, msgs)
+
+		<-ctx.Done()
+
+		h.broker.Unsubscribe(typeUrl, msgs)
+	}()
+
+	return tp
+}
+
+func (h *Handler) dispatch(tp *topic, typeUrl string, msg *eventsv1.Event) {
+	data, err := h.codec.Marshal(msg)
+	if err != nil {
+		h.log.Error("failed to marshal event", "typeUrl", typeUrl, "error", err)
+		return
+	}
+
+	var key string
+	if msg.Retained {
+		key = h.broker.RetainedKey(msg)
+	}
+
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	h.seq++
+	e := entry{
+		id:      h.seq,
+		typeUrl: typeUrl,
+		data:    data,
+	}
+
+	tp.backlog = append(tp.backlog, e)
+	if len(tp.backlog) > backlogSize {
+		tp.backlog = tp.backlog[len(tp.backlog)-backlogSize:]
+	}
+
+	if msg.Retained {
+		tp.retained[key] = e
+	}
+
+	for client := range tp.clients {
+		select {
+		case client <- e:
+		default:
+			// drop the client from all topics and close the channel
+			for _, other := range h.topics {
+				delete(other.clients, client)
+			}
+
+			close(client)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1/eventsv1connect"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/httpauth"
+	"github.com/tierklinik-dobersberg/events-service/internal/ratelimit"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type mockBroker struct {
+	l             sync.Mutex
+	subscriptions map[string]chan *eventsv1.Event
+	clients       []broker.ClientInfo
+}
+
+func (m *mockBroker) Subscribe(topic string, msgs chan *eventsv1.Event) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.subscriptions == nil {
+		m.subscriptions = make(map[string]chan *eventsv1.Event)
+	}
+
+	m.subscriptions[topic] = msgs
+}
+
+func (m *mockBroker) Unsubscribe(topic string, msgs chan *eventsv1.Event) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.subscriptions[topic] == msgs {
+		delete(m.subscriptions, topic)
+	}
+}
+
+func (m *mockBroker) RegisterClient(_ chan *eventsv1.Event, info broker.ClientInfo, _ func()) (string, func()) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.clients = append(m.clients, info)
+
+	return "id", func() {}
+}
+
+// RetainedKey keys retained events by the part of the string value before
+// the first colon.
+func (m *mockBroker) RetainedKey(evt *eventsv1.Event) string {
+	var value wrapperspb.StringValue
+	if err := evt.Event.UnmarshalTo(&value); err != nil {
+		return ""
+	}
+
+	key, _, _ := strings.Cut(value.Value, ":")
+
+	return key
+}
+
+func (m *mockBroker) subscribed(topic string) bool {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.subscriptions[topic] != nil
+}
+
+func (m *mockBroker) send(t *testing.T, topic string, value string) {
+	t.Helper()
+
+	m.publish(t, topic, value, false)
+}
+
+func (m *mockBroker) publish(t *testing.T, topic string, value string, retained bool) {
+	t.Helper()
+
+	evt, err := anypb.New(wrapperspb.String(value))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return m.subscribed(topic)
+	}, time.Second, time.Millisecond)
+
+	m.l.Lock()
+	ch := m.subscriptions[topic]
+	m.l.Unlock()
+
+	ch <- &eventsv1.Event{Event: evt, Retained: retained}
+}
+
+type sseEvent struct {
+	id   string
+	typ  string
+	data string
+}
+
+func readEvents(t *testing.T, scanner *bufio.Scanner, count int) []sseEvent {
+	t.Helper()
+
+	var (
+		result  []sseEvent
+		current sseEvent
+	)
+
+	for len(result) < count && scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if current.id != "" {
+				result = append(result, current)
+			}
+			current = sseEvent{}
+
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+
+		case strings.HasPrefix(line, "event: "):
+			current.typ = strings.TrimPrefix(line, "event: ")
+
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	require.Len(t, result, count)
+
+	return result
+}
+
+func TestServeEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := &mockBroker{}
+	srv := httptest.NewServer(NewHandler(ctx, b, nil, nil, nil))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "?type=google.protobuf.StringValue")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	b.send(t, "google.protobuf.StringValue", "first")
+	b.send(t, "google.protobuf.StringValue", "second")
+	b.send(t, "google.protobuf.StringValue", "third")
+
+	events := readEvents(t, bufio.NewScanner(res.Body), 3)
+
+	require.Equal(t, "google.protobuf.StringValue", events[0].typ)
+	require.JSONEq(t, `{"event": {"@type": "type.googleapis.com/google.protobuf.StringValue", "value": "first"}}`, events[0].data)
+
+	// resume after the first event
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"?type=google.protobuf.StringValue", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", events[0].id)
+
+	resumed, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+
+	replayed := readEvents(t, bufio.NewScanner(resumed.Body), 2)
+	require.Equal(t, events[1:], replayed)
+}
+
+func TestReplayRetainedPerKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := &mockBroker{}
+	srv := httptest.NewServer(NewHandler(ctx, b, nil, nil, nil))
+	defer srv.Close()
+
+	first, err := http.Get(srv.URL + "?type=google.protobuf.StringValue")
+	require.NoError(t, err)
+	defer first.Body.Close()
+
+	b.publish(t, "google.protobuf.StringValue", "room1:busy", true)
+	b.publish(t, "google.protobuf.StringValue", "room2:busy", true)
+	b.publish(t, "google.protobuf.StringValue", "room1:free", true)
+	b.send(t, "google.protobuf.StringValue", "live")
+
+	// wait until all events have been dispatched
+	readEvents(t, bufio.NewScanner(first.Body), 4)
+
+	// a new client receives the latest retained state of each key
+	res, err := http.Get(srv.URL + "?type=google.protobuf.StringValue")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	events := readEvents(t, bufio.NewScanner(res.Body), 2)
+
+	require.JSONEq(t, `{"event": {"@type": "type.googleapis.com/google.protobuf.StringValue", "value": "room2:busy"}, "retained": true}`, events[0].data)
+	require.JSONEq(t, `{"event": {"@type": "type.googleapis.com/google.protobuf.StringValue", "value": "room1:free"}, "retained": true}`, events[1].data)
+}
+
+func TestMissingType(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(context.Background(), &mockBroker{}, nil, nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/sse", nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := &mockBroker{}
+	limiter := ratelimit.New(config.RateLimits{SubscriptionsPerUser: 1})
+
+	srv := httptest.NewServer(NewHandler(ctx, b, nil, httpauth.New(eventsv1connect.EventServiceSubscribeProcedure, auth.NewAuthAnnotationInterceptor(protoregistry.GlobalFiles, nil, nil)), limiter))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "?type=google.protobuf.StringValue")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"?type=google.protobuf.StringValue", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Remote-User-ID", "alice")
+
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	b.l.Lock()
+	require.Len(t, b.clients, 1)
+	require.Equal(t, "sse", b.clients[0].Kind)
+	require.Equal(t, "alice", b.clients[0].User)
+	require.Equal(t, []string{"google.protobuf.StringValue"}, b.clients[0].TypeURLs)
+	b.l.Unlock()
+
+	// the subscription quota of alice is exhausted
+	second, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	second.Body.Close()
+
+	require.Equal(t, http.StatusTooManyRequests, second.StatusCode)
+}
+
+func TestUnsubscribeIdleTypes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := &mockBroker{}
+	h := NewHandler(ctx, b, nil, nil, nil)
+	h.Linger = 10 * time.Millisecond
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	reqCtx, cancelReq := context.WithCancel(ctx)
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, srv.URL+"?type=google.protobuf.StringValue", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Eventually(t, func() bool {
+		return b.subscribed("google.protobuf.StringValue")
+	}, time.Second, time.Millisecond)
+
+	cancelReq()
+
+	require.Eventually(t, func() bool {
+		return !b.subscribed("google.protobuf.StringValue")
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,389 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	eventsservicev1 "github.com/tierklinik-dobersberg/events-service/gen/go/tkd/eventsservice/v1"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrNotFound is returned if a key or namespace does not exist.
+var ErrNotFound = errors.New("not found")
+
+var bucketName = []byte("storage")
+
+// pruneInterval is the interval at which expired entries are removed.
+const pruneInterval = time.Minute
+
+// Store is a persistent key/value store for automation bundles backed by an
+// embedded bbolt database. Each bundle has its own namespace.
+type Store struct {
+	db  *bolt.DB
+	log *slog.Logger
+}
+
+// Namespace returns the namespace of the automation bundle loaded from
+// source. It is the base name of source without an archive extension so the
+// data is kept if AUTOMATION_PATH is mounted elsewhere or a bundle is
+// converted between a directory and an archive.
+func Namespace(source string) string {
+	name := filepath.Base(source)
+
+	for _, ext := range []string{".tar.gz", ".tar", ".zip"} {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok && trimmed != "" {
+			return trimmed
+		}
+	}
+
+	return name
+}
+
+// Open opens or creates the storage database at path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage database: %w", err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	}); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return &Store{
+		db:  db,
+		log: slog.Default().With("subsystem", "storage"),
+	}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Run periodically removes expired entries until ctx is cancelled.
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			count, err := s.Prune(time.Now())
+			if err != nil {
+				s.log.Error("failed to prune expired entries", "error", err)
+			} else if count > 0 {
+				s.log.Debug("pruned expired entries", "count", count)
+			}
+		}
+	}
+}
+
+// Get returns the value stored for key in the namespace of bundle.
+func (s *Store) Get(bundle, key string) (*structpb.Value, error) {
+	var value *structpb.Value
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		entry, err := get(namespace(tx, bundle), key, time.Now())
+		if err != nil {
+			return err
+		}
+
+		value = entry.Value
+
+		return nil
+	})
+
+	return value, err
+}
+
+// Set stores value for key in the namespace of bundle. If ttl is positive,
+// the entry expires after ttl.
+func (s *Store) Set(bundle, key string, value *structpb.Value, ttl time.Duration) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		ns, err := createNamespace(tx, bundle)
+		if err != nil {
+			return err
+		}
+
+		return put(ns, key, value, ttl, time.Now())
+	})
+}
+
+// CompareAndSet atomically stores value for key if the current value equals
+// expected. A nil expected value requires the key to not exist. It reports
+// whether the value has been stored.
+func (s *Store) CompareAndSet(bundle, key string, expected, value *structpb.Value, ttl time.Duration) (bool, error) {
+	var swapped bool
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		ns, err := createNamespace(tx, bundle)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		current, err := get(ns, key, now)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if expected != nil {
+				return nil
+			}
+
+		case err != nil:
+			return err
+
+		case expected == nil || !proto.Equal(current.Value, expected):
+			return nil
+		}
+
+		swapped = true
+
+		return put(ns, key, value, ttl, now)
+	})
+
+	return swapped, err
+}
+
+// Delete removes key from the namespace of bundle. It returns ErrNotFound if
+// the key does not exist.
+func (s *Store) Delete(bundle, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		ns := namespace(tx, bundle)
+
+		if _, err := get(ns, key, time.Now()); err != nil {
+			return err
+		}
+
+		return ns.Delete([]byte(key))
+	})
+}
+
+// List returns all entries of bundle whose key starts with prefix, ordered
+// by key.
+func (s *Store) List(bundle, prefix string) ([]*eventsservicev1.StorageEntry, error) {
+	var result []*eventsservicev1.StorageEntry
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		ns := namespace(tx, bundle)
+		if ns == nil {
+			return nil
+		}
+
+		now := time.Now()
+		c := ns.Cursor()
+
+		for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+			entry, err := unmarshal(v)
+			if err != nil {
+				return err
+			}
+
+			if expired(entry, now) {
+				continue
+			}
+
+			result = append(result, entry)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Namespaces returns all bundles that have stored data, ordered by name.
+func (s *Store) Namespaces() ([]*eventsservicev1.StorageNamespace, error) {
+	var result []*eventsservicev1.StorageNamespace
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		now := time.Now()
+
+		return tx.Bucket(bucketName).ForEachBucket(func(name []byte) error {
+			ns := &eventsservicev1.StorageNamespace{
+				Bundle: string(name),
+			}
+
+			if err := tx.Bucket(bucketName).Bucket(name).ForEach(func(_, v []byte) error {
+				entry, err := unmarshal(v)
+				if err != nil {
+					return err
+				}
+
+				if !expired(entry, now) {
+					ns.Keys++
+				}
+
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			result = append(result, ns)
+
+			return nil
+		})
+	})
+
+	return result, err
+}
+
+// Wipe removes all data of bundle and returns the number of removed
+// entries. It returns ErrNotFound if bundle has never stored any data.
+func (s *Store) Wipe(bundle string) (int, error) {
+	var count int
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		ns := namespace(tx, bundle)
+		if ns == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, bundle)
+		}
+
+		count = ns.Stats().KeyN
+
+		return tx.Bucket(bucketName).DeleteBucket([]byte(bundle))
+	})
+
+	return count, err
+}
+
+// Prune removes all entries that expired before now and returns the number
+// of removed entries.
+func (s *Store) Prune(now time.Time) (int, error) {
+	var count int
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(bucketName)
+
+		return root.ForEachBucket(func(name []byte) error {
+			ns := root.Bucket(name)
+
+			// deleting while iterating skips entries, collect the expired
+			// keys first.
+			var keys [][]byte
+			if err := ns.ForEach(func(k, v []byte) error {
+				entry, err := unmarshal(v)
+				if err != nil {
+					return err
+				}
+
+				if expired(entry, now) {
+					keys = append(keys, bytes.Clone(k))
+				}
+
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			for _, k := range keys {
+				if err := ns.Delete(k); err != nil {
+					return err
+				}
+			}
+
+			count += len(keys)
+
+			return nil
+		})
+	})
+
+	return count, err
+}
+
+// namespace returns the bucket of bundle or nil if it does not exist.
+func namespace(tx *bolt.Tx, bundle string) *bolt.Bucket {
+	if bundle == "" {
+		return nil
+	}
+
+	return tx.Bucket(bucketName).Bucket([]byte(bundle))
+}
+
+func createNamespace(tx *bolt.Tx, bundle string) (*bolt.Bucket, error) {
+	if bundle == "" {
+		return nil, errors.New("missing bundle name")
+	}
+
+	return tx.Bucket(bucketName).CreateBucketIfNotExists([]byte(bundle))
+}
+
+func get(ns *bolt.Bucket, key string, now time.Time) (*eventsservicev1.StorageEntry, error) {
+	if ns == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	v := ns.Get([]byte(key))
+	if v == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	entry, err := unmarshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if expired(entry, now) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return entry, nil
+}
+
+func put(ns *bolt.Bucket, key string, value *structpb.Value, ttl time.Duration, now time.Time) error {
+	if strings.TrimSpace(key) == "" {
+		return errors.New("missing key")
+	}
+
+	if value == nil {
+		value = structpb.NewNullValue()
+	}
+
+	entry := &eventsservicev1.StorageEntry{
+		Key:        key,
+		Value:      value,
+		UpdateTime: timestamppb.New(now),
+	}
+
+	if ttl > 0 {
+		entry.ExpireTime = timestamppb.New(now.Add(ttl))
+	}
+
+	blob, err := proto.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return ns.Put([]byte(key), blob)
+}
+
+func unmarshal(v []byte) (*eventsservicev1.StorageEntry, error) {
+	entry := new(eventsservicev1.StorageEntry)
+	if err := proto.Unmarshal(v, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func expired(entry *eventsservicev1.StorageEntry, now time.Time) bool {
+	return entry.ExpireTime != nil && !entry.ExpireTime.AsTime().After(now)
+}
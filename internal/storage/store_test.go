@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestStore(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Get("a", "foo")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("a", "foo", structpb.NewStringValue("bar"), 0))
+	require.NoError(t, store.Set("a", "fizz", structpb.NewNumberValue(1), 0))
+	require.NoError(t, store.Set("b", "foo", structpb.NewBoolValue(true), 0))
+
+	value, err := store.Get("a", "foo")
+	require.NoError(t, err)
+	require.Equal(t, "bar", value.GetStringValue())
+
+	// namespaces are isolated
+	value, err = store.Get("b", "foo")
+	require.NoError(t, err)
+	require.True(t, value.GetBoolValue())
+
+	entries, err := store.List("a", "f")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "fizz", entries[0].Key)
+	require.Equal(t, "foo", entries[1].Key)
+
+	entries, err = store.List("a", "fo")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	namespaces, err := store.Namespaces()
+	require.NoError(t, err)
+	require.Len(t, namespaces, 2)
+	require.Equal(t, "a", namespaces[0].Bundle)
+	require.Equal(t, int64(2), namespaces[0].Keys)
+
+	require.NoError(t, store.Delete("a", "foo"))
+	require.ErrorIs(t, store.Delete("a", "foo"), ErrNotFound)
+
+	count, err := store.Wipe("a")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	_, err = store.Wipe("a")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStoreCompareAndSet(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	one := structpb.NewNumberValue(1)
+	two := structpb.NewNumberValue(2)
+
+	// a nil expected value requires the key to not exist
+	swapped, err := store.CompareAndSet("a", "counter", nil, one, 0)
+	require.NoError(t, err)
+	require.True(t, swapped)
+
+	swapped, err = store.CompareAndSet("a", "counter", nil, one, 0)
+	require.NoError(t, err)
+	require.False(t, swapped)
+
+	swapped, err = store.CompareAndSet("a", "counter", two, two, 0)
+	require.NoError(t, err)
+	require.False(t, swapped)
+
+	swapped, err = store.CompareAndSet("a", "counter", one, two, 0)
+	require.NoError(t, err)
+	require.True(t, swapped)
+
+	value, err := store.Get("a", "counter")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(two, value))
+}
+
+func TestStoreTTL(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.Set("a", "short", structpb.NewNullValue(), time.Millisecond))
+	require.NoError(t, store.Set("a", "long", structpb.NewNullValue(), time.Hour))
+	require.NoError(t, store.Set("a", "forever", structpb.NewNullValue(), 0))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = store.Get("a", "short")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	entries, err := store.List("a", "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// expired entries may be replaced by CompareAndSet with a nil value
+	swapped, err := store.CompareAndSet("a", "short", nil, structpb.NewNullValue(), time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, swapped)
+
+	count, err := store.Prune(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	entries, err = store.List("a", "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "forever", entries[0].Key)
+}
+
+func TestNamespace(t *testing.T) {
+	cases := map[string]string{
+		"/srv/automation/reminders":        "reminders",
+		"/mnt/automation/reminders.zip":    "reminders",
+		"/srv/automation/reminders.tar":    "reminders",
+		"/srv/automation/reminders.tar.gz": "reminders",
+		"reminders.v2":                     "reminders.v2",
+		"/srv/automation/.zip":             ".zip",
+	}
+
+	for source, expected := range cases {
+		require.Equal(t, expected, Namespace(source), source)
+	}
+}
@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// EventField is the field number used to carry the trace context in an
+// eventsv1.Event. The field is encoded like `map<string, string>` so it can
+// be read by consumers that declare it but is ignored as an unknown field by
+// everyone else.
+const EventField protowire.Number = 15000
+
+// Inject stores the trace context of ctx in evt, replacing any trace context
+// that is already stored.
+func Inject(ctx context.Context, evt *eventsv1.Event) {
+	carrier := make(propagation.MapCarrier)
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	unknown := removeField(evt.ProtoReflect().GetUnknown(), EventField)
+
+	for key, value := range carrier {
+		var entry []byte
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendString(entry, value)
+
+		unknown = protowire.AppendTag(unknown, EventField, protowire.BytesType)
+		unknown = protowire.AppendBytes(unknown, entry)
+	}
+
+	evt.ProtoReflect().SetUnknown(unknown)
+}
+
+// Extract returns a copy of ctx that carries the trace context stored in
+// evt, if any.
+func Extract(ctx context.Context, evt *eventsv1.Event) context.Context {
+	carrier := make(propagation.MapCarrier)
+
+	rangeFields(evt.ProtoReflect().GetUnknown(), func(num protowire.Number, typ protowire.Type, value []byte) {
+		if num != EventField || typ != protowire.BytesType {
+			return
+		}
+
+		entry, n := protowire.ConsumeBytes(value)
+		if n < 0 {
+			return
+		}
+
+		var key, val string
+		rangeFields(entry, func(num protowire.Number, typ protowire.Type, value []byte) {
+			if typ != protowire.BytesType {
+				return
+			}
+
+			s, n := protowire.ConsumeString(value)
+			if n < 0 {
+				return
+			}
+
+			switch num {
+			case 1:
+				key = s
+			case 2:
+				val = s
+			}
+		})
+
+		if key != "" {
+			carrier[key] = val
+		}
+	})
+
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// StartPublish starts a producer span for publishing evt and stores the
+// span's context in evt.
+func StartPublish(ctx context.Context, evt *eventsv1.Event) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, "publish "+evt.GetEvent().GetTypeUrl(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "mqtt"),
+			attribute.String("events.type_url", evt.GetEvent().GetTypeUrl()),
+			attribute.Bool("events.retained", evt.GetRetained()),
+		),
+	)
+
+	Inject(ctx, evt)
+
+	return ctx, span
+}
+
+// StartReceive starts a consumer span for an event received from topic as
+// a child of the trace context stored in evt. The span's context replaces
+// the stored trace context so local subscribers continue the trace from the
+// consumer span.
+func StartReceive(ctx context.Context, topic string, evt *eventsv1.Event) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(Extract(ctx, evt), "receive "+evt.GetEvent().GetTypeUrl(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "mqtt"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("events.type_url", evt.GetEvent().GetTypeUrl()),
+			attribute.Bool("events.retained", evt.GetRetained()),
+		),
+	)
+
+	if span.SpanContext().IsValid() {
+		Inject(ctx, evt)
+	}
+
+	return ctx, span
+}
+
+// rangeFields calls fn for each field in b, value holds the raw field value
+// without the tag. rangeFields stops at the first malformed field.
+func rangeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return
+		}
+
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return
+		}
+
+		fn(num, typ, b[n:n+m])
+
+		b = b[n+m:]
+	}
+}
+
+// removeField returns a copy of b without any occurrence of field num.
+func removeField(b []byte, num protowire.Number) []byte {
+	var result []byte
+
+	for len(b) > 0 {
+		fieldNum, _, n := protowire.ConsumeField(b)
+		if n < 0 {
+			// keep malformed data untouched
+			return append(result, b...)
+		}
+
+		if fieldNum != num {
+			result = append(result, b[:n]...)
+		}
+
+		b = b[n:]
+	}
+
+	return result
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	collectortracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// collector is an in-process OTLP/HTTP collector.
+type collector struct {
+	lock  sync.Mutex
+	spans []*tracev1.Span
+	attrs map[string]string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req collectortracev1.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, rs := range req.ResourceSpans {
+		for _, attr := range rs.GetResource().GetAttributes() {
+			c.attrs[attr.Key] = attr.GetValue().GetStringValue()
+		}
+
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+
+	blob, _ := proto.Marshal(new(collectortracev1.ExportTraceServiceResponse))
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(blob)
+}
+
+func TestSetupExportsSpans(t *testing.T) {
+	col := &collector{attrs: make(map[string]string)}
+
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	shutdown, err := Setup(context.Background(), srv.URL, 1)
+	require.NoError(t, err)
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+
+	evt := &eventsv1.Event{Event: new(anypb.Any)}
+	evt.Event.TypeUrl = "type.googleapis.com/tkd.events.v1.SubscribeRequest"
+
+	_, span := StartPublish(ctx, evt)
+	span.End()
+	parent.End()
+
+	require.NoError(t, shutdown(context.Background()))
+
+	col.lock.Lock()
+	defer col.lock.Unlock()
+
+	require.Equal(t, ServiceName, col.attrs["service.name"])
+	require.Len(t, col.spans, 2)
+
+	names := []string{col.spans[0].Name, col.spans[1].Name}
+	require.ElementsMatch(t, []string{"parent", "publish type.googleapis.com/tkd.events.v1.SubscribeRequest"}, names)
+
+	for _, s := range col.spans {
+		require.Equal(t, col.spans[0].TraceId, s.TraceId)
+	}
+}
+
+func TestSetupRequiresEndpoint(t *testing.T) {
+	_, err := Setup(context.Background(), "", 1)
+	require.Error(t, err)
+}
+
+func TestEventCarrier(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+
+	evt := &eventsv1.Event{Event: new(anypb.Any)}
+
+	// unrelated unknown fields must be preserved
+	evt.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 99, protowire.VarintType), 42))
+
+	Inject(ctx, evt)
+	Inject(ctx, evt) // injecting again replaces the trace context
+
+	blob, err := proto.Marshal(evt)
+	require.NoError(t, err)
+
+	decoded := new(eventsv1.Event)
+	require.NoError(t, proto.Unmarshal(blob, decoded))
+
+	sc := trace.SpanContextFromContext(Extract(context.Background(), decoded))
+	require.True(t, sc.IsValid())
+	require.Equal(t, traceID, sc.TraceID())
+	require.Equal(t, spanID, sc.SpanID())
+	require.True(t, sc.IsSampled())
+
+	var fields []protowire.Number
+	rangeFields(decoded.ProtoReflect().GetUnknown(), func(num protowire.Number, _ protowire.Type, _ []byte) {
+		fields = append(fields, num)
+	})
+	require.Equal(t, []protowire.Number{99, EventField}, fields)
+
+	// events without trace context yield an empty span context
+	sc = trace.SpanContextFromContext(Extract(context.Background(), &eventsv1.Event{}))
+	require.False(t, sc.IsValid())
+}
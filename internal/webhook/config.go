@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Endpoint configures a single webhook receiver.
+type Endpoint struct {
+	// Name is a unique name for the webhook endpoint.
+	Name string `json:"name"`
+
+	// URL is the HTTP(s) URL that events are POSTed to.
+	URL string `json:"url"`
+
+	// Secret is used to calculate the HMAC-SHA256 signature that is sent
+	// in the X-Events-Signature header.
+	Secret string `json:"secret"`
+
+	// Types holds a list of event type URLs that should be delivered
+	// to the webhook.
+	Types []string `json:"types"`
+
+	// Headers may hold additional HTTP headers that are sent with
+	// each request.
+	Headers map[string]string `json:"headers"`
+
+	// MaxAttempts is the maximum number of delivery attempts per event.
+	// Defaults to 5.
+	MaxAttempts int `json:"maxAttempts"`
+
+	// DisableAfter is the number of consecutive failed deliveries after
+	// which the endpoint is disabled. Defaults to 10.
+	DisableAfter int `json:"disableAfter"`
+
+	// Timeout is the timeout for a single delivery attempt. Defaults to 10s.
+	Timeout Duration `json:"timeout"`
+}
+
+// FileConfig is the format of the webhook configuration file.
+type FileConfig struct {
+	Webhooks []Endpoint `json:"webhooks"`
+}
+
+// Duration is a time.Duration that is encoded as a string in JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(blob []byte) error {
+	var s string
+	if err := json.Unmarshal(blob, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadFile loads the webhook endpoint configuration from path.
+func LoadFile(path string) ([]Endpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook configuration: %w", err)
+	}
+
+	var cfg FileConfig
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook configuration: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(cfg.Webhooks))
+	for idx, ep := range cfg.Webhooks {
+		if ep.Name == "" {
+			return nil, fmt.Errorf("webhook #%d: missing name", idx)
+		}
+
+		if _, ok := names[ep.Name]; ok {
+			return nil, fmt.Errorf("webhook %q: duplicate name", ep.Name)
+		}
+		names[ep.Name] = struct{}{}
+
+		if ep.URL == "" {
+			return nil, fmt.Errorf("webhook %q: missing url", ep.Name)
+		}
+
+		if len(ep.Types) == 0 {
+			return nil, fmt.Errorf("webhook %q: no event types configured", ep.Name)
+		}
+	}
+
+	return cfg.Webhooks, nil
+}
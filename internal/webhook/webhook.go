@@ -0,0 +1,404 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/codec"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 signature of the
+	// request body, prefixed with "sha256=".
+	SignatureHeader = "X-Events-Signature"
+
+	// DeliveryHeader holds a unique ID for each delivery. The ID is the same
+	// for all attempts of a delivery.
+	DeliveryHeader = "X-Events-Delivery"
+
+	// TypeHeader holds the type URL of the delivered event.
+	TypeHeader = "X-Events-Type"
+
+	// maxDeliveryLog is the number of deliveries kept per endpoint.
+	maxDeliveryLog = 100
+
+	// queueSize is the number of events buffered per endpoint. Events are
+	// dropped if the queue of an endpoint is full.
+	queueSize = 100
+)
+
+// ErrUnknownEndpoint is returned by Enable if no endpoint with the given
+// name is configured.
+var ErrUnknownEndpoint = errors.New("unknown webhook endpoint")
+
+type Broker interface {
+	Subscribe(string, chan *eventsv1.Event)
+	UnsubscribeAll(chan *eventsv1.Event)
+}
+
+// Delivery describes the result of delivering an event to a webhook
+// endpoint.
+type Delivery struct {
+	ID         string    `json:"id"`
+	TypeURL    string    `json:"typeUrl"`
+	Time       time.Time `json:"time"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+}
+
+// Status describes the current state of a webhook endpoint.
+type Status struct {
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	Types               []string   `json:"types"`
+	Disabled            bool       `json:"disabled"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	Dropped             int        `json:"dropped"`
+	Deliveries          []Delivery `json:"deliveries"`
+}
+
+type endpoint struct {
+	Endpoint
+
+	lock                sync.Mutex
+	disabled            bool
+	consecutiveFailures int
+	dropped             int
+	deliveries          []Delivery
+
+	queue chan *eventsv1.Event
+}
+
+// Manager subscribes to the events configured for each webhook endpoint
+// and delivers them as JSON using HTTP POST requests.
+type Manager struct {
+	broker    Broker
+	codec     *codec.JSON
+	client    *http.Client
+	endpoints []*endpoint
+	log       *slog.Logger
+
+	// InitialBackoff is the time to wait before the first retry. Each
+	// subsequent retry doubles the backoff up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewManager returns a new webhook manager for endpoints. resolver is used to
+// marshal events to JSON and may be nil.
+func NewManager(broker Broker, resolver codec.Resolver, endpoints []Endpoint) *Manager {
+	m := &Manager{
+		broker:         broker,
+		codec:          codec.NewCodec(resolver),
+		client:         &http.Client{},
+		log:            slog.Default().With("subsystem", "webhook"),
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+
+	for _, ep := range endpoints {
+		if ep.MaxAttempts <= 0 {
+			ep.MaxAttempts = 5
+		}
+
+		if ep.DisableAfter <= 0 {
+			ep.DisableAfter = 10
+		}
+
+		if ep.Timeout <= 0 {
+			ep.Timeout = Duration(10 * time.Second)
+		}
+
+		m.endpoints = append(m.endpoints, &endpoint{
+			Endpoint: ep,
+			queue:    make(chan *eventsv1.Event, queueSize),
+		})
+	}
+
+	return m
+}
+
+// Start subscribes to all configured event types and starts delivering
+// events until ctx is cancelled.
+//
+// Each endpoint has its own queue and delivery worker. Events are handed
+// over to the queue without blocking so a slow or dead endpoint never
+// stalls the broker or other endpoints. Events that do not fit into the
+// queue of an endpoint are dropped.
+func (m *Manager) Start(ctx context.Context) {
+	for _, ep := range m.endpoints {
+		msgs := make(chan *eventsv1.Event, 1)
+
+		for _, t := range ep.Types {
+			m.broker.Subscribe(t, msgs)
+		}
+
+		go func() {
+			<-ctx.Done()
+
+			m.broker.UnsubscribeAll(msgs)
+
+			close(msgs)
+		}()
+
+		go func() {
+			defer close(ep.queue)
+
+			for evt := range msgs {
+				m.enqueue(ep, evt)
+			}
+		}()
+
+		go func() {
+			for evt := range ep.queue {
+				ep.lock.Lock()
+				disabled := ep.disabled
+				ep.lock.Unlock()
+
+				if disabled {
+					continue
+				}
+
+				m.deliver(ctx, ep, evt)
+			}
+		}()
+
+		m.log.Info("webhook endpoint started", "name", ep.Name, "types", ep.Types)
+	}
+}
+
+// Enable re-enables the endpoint name after it has been disabled due to
+// repeated delivery failures.
+func (m *Manager) Enable(name string) error {
+	for _, ep := range m.endpoints {
+		if ep.Name != name {
+			continue
+		}
+
+		ep.lock.Lock()
+		defer ep.lock.Unlock()
+
+		ep.disabled = false
+		ep.consecutiveFailures = 0
+
+		m.log.Info("webhook endpoint enabled", "name", ep.Name)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownEndpoint, name)
+}
+
+func (m *Manager) enqueue(ep *endpoint, evt *eventsv1.Event) {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	if ep.disabled {
+		return
+	}
+
+	select {
+	case ep.queue <- evt:
+	default:
+		ep.dropped++
+		m.log.Warn("webhook queue full, dropping event", "name", ep.Name, "typeUrl", evt.GetEvent().GetTypeUrl(), "dropped", ep.dropped)
+	}
+}
+
+// Status returns the current status of all webhook endpoints.
+func (m *Manager) Status() []Status {
+	result := make([]Status, 0, len(m.endpoints))
+
+	for _, ep := range m.endpoints {
+		ep.lock.Lock()
+
+		result = append(result, Status{
+			Name:                ep.Name,
+			URL:                 ep.URL,
+			Types:               ep.Types,
+			Disabled:            ep.disabled,
+			ConsecutiveFailures: ep.consecutiveFailures,
+			Dropped:             ep.dropped,
+			Deliveries:          append([]Delivery(nil), ep.deliveries...),
+		})
+
+		ep.lock.Unlock()
+	}
+
+	return result
+}
+
+// ServeHTTP serves the status of all webhook endpoints as JSON. A POST
+// request with an "enable" query parameter re-enables the named endpoint.
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		name := r.URL.Query().Get("enable")
+		if name == "" {
+			http.Error(w, "missing enable parameter", http.StatusBadRequest)
+			return
+		}
+
+		if err := m.Enable(name); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(m.Status()); err != nil {
+		m.log.Error("failed to encode webhook status", "error", err)
+	}
+}
+
+func (m *Manager) deliver(ctx context.Context, ep *endpoint, evt *eventsv1.Event) {
+	typeUrl := strings.TrimPrefix(evt.GetEvent().GetTypeUrl(), "type.googleapis.com/")
+
+	delivery := Delivery{
+		ID:      newDeliveryID(),
+		TypeURL: typeUrl,
+		Time:    time.Now(),
+	}
+
+	body, err := m.codec.Marshal(evt)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("failed to marshal event: %s", err)
+		m.record(ep, delivery)
+
+		return
+	}
+
+	backoff := m.InitialBackoff
+
+	for delivery.Attempts < ep.MaxAttempts {
+		if delivery.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				delivery.Error = ctx.Err().Error()
+				m.record(ep, delivery)
+
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > m.MaxBackoff {
+				backoff = m.MaxBackoff
+			}
+		}
+
+		delivery.Attempts++
+
+		delivery.StatusCode, err = m.post(ctx, ep, delivery, body)
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+
+			break
+		}
+
+		delivery.Error = err.Error()
+
+		m.log.Warn("failed to deliver webhook", "name", ep.Name, "typeUrl", typeUrl, "attempt", delivery.Attempts, "error", err)
+	}
+
+	m.record(ep, delivery)
+}
+
+func (m *Manager) post(ctx context.Context, ep *endpoint, delivery Delivery, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(ep.Timeout))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	for key, value := range ep.Headers {
+		req.Header.Set(key, value)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TypeHeader, delivery.TypeURL)
+
+	if ep.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign([]byte(ep.Secret), body))
+	}
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+func (m *Manager) record(ep *endpoint, delivery Delivery) {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	ep.deliveries = append(ep.deliveries, delivery)
+	if len(ep.deliveries) > maxDeliveryLog {
+		ep.deliveries = ep.deliveries[len(ep.deliveries)-maxDeliveryLog:]
+	}
+
+	if delivery.Success {
+		ep.consecutiveFailures = 0
+		m.log.Debug("webhook delivered", "name", ep.Name, "typeUrl", delivery.TypeURL, "attempts", delivery.Attempts)
+
+		return
+	}
+
+	ep.consecutiveFailures++
+	m.log.Error("webhook delivery failed", "name", ep.Name, "typeUrl", delivery.TypeURL, "attempts", delivery.Attempts, "error", delivery.Error)
+
+	if ep.consecutiveFailures >= ep.DisableAfter {
+		ep.disabled = true
+		m.log.Error("disabling webhook endpoint after repeated failures", "name", ep.Name, "failures", ep.consecutiveFailures)
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of body using secret.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
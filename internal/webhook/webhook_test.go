@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type mockBroker struct {
+	l             sync.Mutex
+	subscriptions map[string]chan *eventsv1.Event
+}
+
+func (m *mockBroker) Subscribe(topic string, msgs chan *eventsv1.Event) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.subscriptions == nil {
+		m.subscriptions = make(map[string]chan *eventsv1.Event)
+	}
+
+	m.subscriptions[topic] = msgs
+}
+
+func (m *mockBroker) UnsubscribeAll(msgs chan *eventsv1.Event) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	for key, ch := range m.subscriptions {
+		if ch == msgs {
+			delete(m.subscriptions, key)
+		}
+	}
+}
+
+func (m *mockBroker) send(t *testing.T, topic string) {
+	t.Helper()
+
+	value, err := structpb.NewStruct(map[string]any{"foo": "bar"})
+	require.NoError(t, err)
+
+	evt, err := anypb.New(value)
+	require.NoError(t, err)
+
+	m.l.Lock()
+	ch := m.subscriptions[topic]
+	m.l.Unlock()
+
+	require.NotNil(t, ch)
+
+	ch <- &eventsv1.Event{Event: evt}
+}
+
+func TestDeliveryWithRetry(t *testing.T) {
+	var calls atomic.Int32
+	bodies := make(chan []byte, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if r.Header.Get(SignatureHeader) != "sha256="+Sign([]byte("secret"), body) {
+			t.Errorf("invalid signature header: %q", r.Header.Get(SignatureHeader))
+		}
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	b := &mockBroker{}
+	m := NewManager(b, nil, []Endpoint{
+		{
+			Name:   "test",
+			URL:    srv.URL,
+			Secret: "secret",
+			Types:  []string{"google.protobuf.Struct"},
+		},
+	})
+	m.InitialBackoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.Start(ctx)
+	b.send(t, "google.protobuf.Struct")
+
+	select {
+	case body := <-bodies:
+		require.JSONEq(t, `{"event": {"@type": "type.googleapis.com/google.protobuf.Struct", "value": {"foo": "bar"}}}`, string(body))
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	require.Eventually(t, func() bool {
+		status := m.Status()
+		return len(status[0].Deliveries) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	status := m.Status()[0]
+	require.True(t, status.Deliveries[0].Success)
+	require.Equal(t, 2, status.Deliveries[0].Attempts)
+	require.Equal(t, 0, status.ConsecutiveFailures)
+}
+
+func TestDisableAfterRepeatedFailures(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	b := &mockBroker{}
+	m := NewManager(b, nil, []Endpoint{
+		{
+			Name:         "test",
+			URL:          srv.URL,
+			Types:        []string{"google.protobuf.Struct"},
+			MaxAttempts:  2,
+			DisableAfter: 2,
+		},
+	})
+	m.InitialBackoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.Start(ctx)
+
+	for i := 0; i < 3; i++ {
+		b.send(t, "google.protobuf.Struct")
+	}
+
+	require.Eventually(t, func() bool {
+		return m.Status()[0].Disabled
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// give the manager a chance to (wrongly) deliver the third event
+	time.Sleep(50 * time.Millisecond)
+
+	status := m.Status()[0]
+	require.Len(t, status.Deliveries, 2)
+	require.Equal(t, 2, status.ConsecutiveFailures)
+	require.Equal(t, int32(4), calls.Load())
+	require.Equal(t, http.StatusBadGateway, status.Deliveries[0].StatusCode)
+}
+
+func TestDeadEndpointDoesNotBlock(t *testing.T) {
+	block := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	b := &mockBroker{}
+	m := NewManager(b, nil, []Endpoint{
+		{
+			Name:  "test",
+			URL:   srv.URL,
+			Types: []string{"google.protobuf.Struct"},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.Start(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 3*queueSize; i++ {
+			b.send(t, "google.protobuf.Struct")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending to a blocked endpoint did not return")
+	}
+
+	require.Eventually(t, func() bool {
+		return m.Status()[0].Dropped > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEnableEndpoint(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+
+	bodies := make(chan []byte, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	b := &mockBroker{}
+	m := NewManager(b, nil, []Endpoint{
+		{
+			Name:         "test",
+			URL:          srv.URL,
+			Types:        []string{"google.protobuf.Struct"},
+			MaxAttempts:  1,
+			DisableAfter: 1,
+		},
+	})
+	m.InitialBackoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.Start(ctx)
+	b.send(t, "google.protobuf.Struct")
+
+	require.Eventually(t, func() bool {
+		return m.Status()[0].Disabled
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.ErrorIs(t, m.Enable("unknown"), ErrUnknownEndpoint)
+
+	fail.Store(false)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks?enable=test", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	status := m.Status()[0]
+	require.False(t, status.Disabled)
+	require.Equal(t, 0, status.ConsecutiveFailures)
+
+	b.send(t, "google.protobuf.Struct")
+
+	select {
+	case <-bodies:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered after enabling the endpoint")
+	}
+}
@@ -0,0 +1,162 @@
+package ws
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/gorilla/websocket"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"github.com/tierklinik-dobersberg/events-service/internal/codec"
+	"github.com/tierklinik-dobersberg/events-service/internal/httpauth"
+	"github.com/tierklinik-dobersberg/events-service/internal/ratelimit"
+)
+
+// Handler is a WebSocket gateway for event subscriptions. Clients send
+// tkd.events.v1.SubscribeRequest messages encoded as JSON, for example
+// {"subscribe": "tkd.roster.v1.RosterChangedEvent"}, and receive
+// tkd.events.v1.Event messages encoded as JSON.
+type Handler struct {
+	broker   *broker.Broker
+	auth     *httpauth.Authenticator
+	limiter  *ratelimit.Limiter
+	codec    *codec.JSON
+	upgrader websocket.Upgrader
+	log      *slog.Logger
+}
+
+// NewHandler returns a new WebSocket handler. allowedOrigins is checked against
+// the Origin header of the upgrade request, "*" allows any origin.
+// resolver is used to marshal events to JSON and may be nil. authenticator
+// and limiter may be nil to disable authentication and subscription quotas.
+func NewHandler(b *broker.Broker, resolver codec.Resolver, allowedOrigins []string, authenticator *httpauth.Authenticator, limiter *ratelimit.Limiter) *Handler {
+	return &Handler{
+		broker:  b,
+		auth:    authenticator,
+		limiter: limiter,
+		codec:   codec.NewCodec(resolver),
+		log:     slog.Default().With("subsystem", "websocket"),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" || slices.Contains(allowedOrigins, "*") {
+					return true
+				}
+
+				if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+					return true
+				}
+
+				return slices.Contains(allowedOrigins, origin)
+			},
+		},
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := h.auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), httpauth.StatusCode(err))
+		return
+	}
+
+	release, err := h.limiter.AcquireSubscription(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied with an HTTP error
+		h.log.Error("failed to upgrade websocket connection", "peer", r.RemoteAddr, "error", err)
+		return
+	}
+	defer conn.Close()
+
+	// the request context is not cancelled when the client closes a
+	// hijacked connection so the stream cancels ctx once reading fails.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	stream := &stream{
+		conn:   conn,
+		codec:  h.codec,
+		cancel: cancel,
+		peer: connect.Peer{
+			Addr:     r.RemoteAddr,
+			Protocol: "websocket",
+		},
+	}
+
+	subscriber := broker.NewSubscriber(stream, h.broker).WithClientInfo("websocket", user.ID)
+
+	if err := subscriber.Handle(ctx); err != nil {
+		h.log.Error("failed to handle subscription", "peer", r.RemoteAddr, "error", err)
+	}
+
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+}
+
+type stream struct {
+	conn   *websocket.Conn
+	codec  *codec.JSON
+	cancel context.CancelFunc
+	peer   connect.Peer
+
+	writeLock sync.Mutex
+}
+
+func (s *stream) Send(evt *eventsv1.Event) error {
+	blob, err := s.codec.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	return s.conn.WriteMessage(websocket.TextMessage, blob)
+}
+
+func (s *stream) Receive() (*eventsv1.SubscribeRequest, error) {
+	for {
+		msgType, blob, err := s.conn.ReadMessage()
+		if err != nil {
+			s.cancel()
+
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil, io.EOF
+			}
+
+			return nil, err
+		}
+
+		if msgType != websocket.TextMessage {
+			continue
+		}
+
+		req := new(eventsv1.SubscribeRequest)
+		if err := s.codec.Unmarshal(blob, req); err != nil {
+			s.cancel()
+
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+func (s *stream) Peer() connect.Peer {
+	return s.peer
+}
+
+var _ broker.SubscriberStream = (*stream)(nil)
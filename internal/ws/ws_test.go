@@ -0,0 +1,148 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	eventsv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/events/v1/eventsv1connect"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	"github.com/tierklinik-dobersberg/events-service/internal/broker"
+	"github.com/tierklinik-dobersberg/events-service/internal/config"
+	"github.com/tierklinik-dobersberg/events-service/internal/httpauth"
+	"github.com/tierklinik-dobersberg/events-service/internal/ratelimit"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type fakeMessage struct {
+	mqtt.Message
+
+	topic   string
+	payload []byte
+}
+
+func (m *fakeMessage) Topic() string   { return m.topic }
+func (m *fakeMessage) Payload() []byte { return m.payload }
+func (m *fakeMessage) Retained() bool  { return false }
+
+type fakeClient struct {
+	l        sync.Mutex
+	handlers map[string]mqtt.MessageHandler
+}
+
+func (c *fakeClient) Subscribe(topic string, _ byte, handler mqtt.MessageHandler) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.handlers[topic] = handler
+
+	return nil
+}
+
+func (c *fakeClient) Unsubscribe(topics ...string) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	for _, t := range topics {
+		delete(c.handlers, t)
+	}
+
+	return nil
+}
+
+func (c *fakeClient) Publish(string, byte, bool, []byte) error { return nil }
+
+func (c *fakeClient) handler(topic string) mqtt.MessageHandler {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.handlers[topic]
+}
+
+func TestWebsocketSubscription(t *testing.T) {
+	cli := &fakeClient{handlers: make(map[string]mqtt.MessageHandler)}
+
+	b, err := broker.NewBroker(context.Background(), cli)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(NewHandler(b, nil, []string{"*"}, nil, nil))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"subscribe": "google.protobuf.StringValue"}`)))
+
+	topic := "cis/protobuf/events/google.protobuf.StringValue"
+	require.Eventually(t, func() bool {
+		return cli.handler(topic) != nil
+	}, time.Second, time.Millisecond)
+
+	evt, err := anypb.New(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	payload, err := proto.Marshal(&eventsv1.Event{Event: evt})
+	require.NoError(t, err)
+
+	cli.handler(topic)(nil, &fakeMessage{topic: topic, payload: payload})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"event": {"@type": "type.googleapis.com/google.protobuf.StringValue", "value": "hello"}}`, string(msg))
+
+	// once unsubscribed, the broker should release the MQTT topic
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"unsubscribe": "google.protobuf.StringValue"}`)))
+
+	require.Eventually(t, func() bool {
+		return cli.handler(topic) == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestWebsocketAuthentication(t *testing.T) {
+	cli := &fakeClient{handlers: make(map[string]mqtt.MessageHandler)}
+
+	b, err := broker.NewBroker(context.Background(), cli)
+	require.NoError(t, err)
+
+	limiter := ratelimit.New(config.RateLimits{SubscriptionsPerUser: 1})
+
+	srv := httptest.NewServer(NewHandler(b, nil, []string{"*"}, httpauth.New(eventsv1connect.EventServiceSubscribeProcedure, auth.NewAuthAnnotationInterceptor(protoregistry.GlobalFiles, nil, nil)), limiter))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	header := http.Header{}
+	header.Set("X-Remote-User-ID", "alice")
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool {
+		clients := b.Clients()
+		return len(clients) == 1 && clients[0].User == "alice"
+	}, time.Second, time.Millisecond)
+
+	// the subscription quota of alice is exhausted
+	_, res, err = websocket.DefaultDialer.Dial(url, header)
+	require.Error(t, err)
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+}
@@ -0,0 +1,6 @@
+#!/bin/bash
+
+set -e
+
+echo "Generating protobuf files ..."
+buf generate proto